@@ -0,0 +1,28 @@
+#!/usr/bin/env php
+<?php
+/**
+
+title=expect with operator
+cid=0
+pid=0
+
+equal               >> op:== success
+not equal           >> op:!= fail
+not contain         >> op:!~ error
+ignore case         >> op:~i SUCCESS
+approximate number  >> op:~= 3.14 ±0.01
+less than           >> op:< 100
+lines count         >> op:lines == 2
+
+*/
+
+print("success\n");
+print("pass\n");
+print("no problem\n");
+print("all success\n");
+print("pi=3.1415\n");
+print("api v2 response time 86ms\n");
+print(">>\n");
+print("line 1\n");
+print("line 2\n");
+print(">>\n");
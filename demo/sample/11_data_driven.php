@@ -0,0 +1,21 @@
+#!/usr/bin/env php
+<?php
+/**
+
+title=run once for each data row
+cid=0
+pid=0
+data=
+| user  | password |
+| admin | 123456   |
+| guest | 654321   |
+
+login with user  >> `^[a-z]+$`
+check password   >> `^\d{6}$`
+
+*/
+
+// columns of the row are passed in environment variables,
+// data can also be saved in a csv or json file set by data=file, which is relative to the script.
+print(getenv("user") . "\n");
+print(getenv("password") . "\n");
@@ -0,0 +1,20 @@
+#!/usr/bin/env php
+<?php
+/**
+
+title=checkpoint with label
+cid=0
+pid=0
+
+step 1 >> expect 1
+step 2 >> expect 2
+step 3 >> expect 3
+
+*/
+
+// actual results after '>> step number' are bound to the step, others are matched by position.
+print(">> 3\n");
+print("expect 3\n");
+print(">> 1\n");
+print("expect 1\n");
+print("expect 2\n");
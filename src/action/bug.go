@@ -35,6 +35,13 @@ func CommitBug(files []string) {
 			ids = append(ids, strconv.Itoa(cs.Id))
 		}
 	}
+	// errors of unit test are caused by test setup, not reported as bugs
+	for _, cs := range report.UnitResult {
+		if cs.Status == constant.FAIL.String() {
+			lines = append(lines, fmt.Sprintf("%d. %s.%s %s", cs.Id, cs.TestSuite, cs.Title, logUtils.ColoredStatus(cs.Status)))
+			ids = append(ids, strconv.Itoa(cs.Id))
+		}
+	}
 
 	for {
 		logUtils.PrintToWithColor("\n"+i118Utils.I118Prt.Sprintf("enter_case_id_for_report_bug"), color.FgCyan)
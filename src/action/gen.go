@@ -1,6 +1,7 @@
 package action
 
 import (
+	"github.com/easysoft/zentaoatf/src/service/client"
 	"github.com/easysoft/zentaoatf/src/service/script"
 	"github.com/easysoft/zentaoatf/src/service/zentao"
 	configUtils "github.com/easysoft/zentaoatf/src/utils/config"
@@ -29,7 +30,7 @@ func Generate(productId string, moduleId string, suiteId string, taskId string,
 		return
 	}
 
-	cases, loginFail := zentaoService.LoadTestCases(productId, moduleId, suiteId, taskId)
+	cases, err := zentaoService.LoadTestCases(productId, moduleId, suiteId, taskId)
 
 	if cases != nil && len(cases) > 0 {
 		productId = cases[0].Product
@@ -61,9 +62,9 @@ func Generate(productId string, moduleId string, suiteId string, taskId string,
 		} else {
 			logUtils.PrintToWithColor(err.Error(), color.FgRed)
 		}
+	} else if err != nil {
+		logUtils.PrintToWithColor(client.GetErrorMsg(err), color.FgRed)
 	} else {
-		if !loginFail {
-			logUtils.PrintToWithColor(i118Utils.I118Prt.Sprintf("no_cases"), color.FgRed)
-		}
+		logUtils.PrintToWithColor(i118Utils.I118Prt.Sprintf("no_cases"), color.FgRed)
 	}
 }
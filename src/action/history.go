@@ -0,0 +1,181 @@
+package action
+
+import (
+	"fmt"
+	testingService "github.com/easysoft/zentaoatf/src/service/testing"
+	constant "github.com/easysoft/zentaoatf/src/utils/const"
+	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
+	logUtils "github.com/easysoft/zentaoatf/src/utils/log"
+	"github.com/fatih/color"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const historyTopCount = 5
+
+type historyCaseStat struct {
+	Name     string
+	Runs     int
+	Fails    int
+	Duration float32
+}
+
+// History prints pass rate trend of the last runs, and the slowest and most failing cases in them
+func History(last int) {
+	runs := testingService.ListHistory()
+	if len(runs) == 0 {
+		logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("no_history"), color.FgYellow)
+		return
+	}
+
+	if last > 0 && len(runs) > last {
+		runs = runs[len(runs)-last:]
+	}
+
+	logUtils.Screen(i118Utils.I118Prt.Sprintf("history_runs", len(runs)))
+
+	stats := map[string]*historyCaseStat{}
+	arr := make([]*historyCaseStat, 0)
+	lastRate := -1.0
+	for _, run := range runs {
+		rate := 0.0
+		if run.Total > 0 {
+			rate = float64(run.Pass) * 100 / float64(run.Total)
+		}
+
+		trend := " "
+		if lastRate >= 0 && rate > lastRate {
+			trend = color.GreenString("↑")
+		} else if lastRate >= 0 && rate < lastRate {
+			trend = color.RedString("↓")
+		}
+		lastRate = rate
+
+		logUtils.Screen(i118Utils.I118Prt.Sprintf("history_run",
+			run.Id, time.Unix(run.Time, 0).Format("2006-01-02 15:04:05"), run.TestType+"/"+run.TestFrame,
+			run.Total, run.Pass, run.Fail, run.Skip, rate, trend, run.Duration, run.LogDir))
+
+		report, err := testingService.GetHistoryReport(strconv.Itoa(run.Id))
+		if err != nil {
+			continue
+		}
+
+		keys, cases := testingService.GetHistoryCases(report)
+		for _, key := range keys {
+			cs := cases[key]
+
+			stat, ok := stats[key]
+			if !ok {
+				stat = &historyCaseStat{}
+				stats[key] = stat
+				arr = append(arr, stat)
+			}
+			stat.Name = cs.Name
+			stat.Runs++
+			stat.Duration += cs.Duration
+			if cs.Status == constant.FAIL.String() {
+				stat.Fails++
+			}
+		}
+	}
+
+	sort.SliceStable(arr, func(i, j int) bool {
+		return arr[i].Duration/float32(arr[i].Runs) > arr[j].Duration/float32(arr[j].Runs)
+	})
+	logUtils.Screen("\n" + i118Utils.I118Prt.Sprintf("slowest_cases"))
+	for idx, stat := range arr {
+		if idx >= historyTopCount || stat.Duration == 0 {
+			break
+		}
+		logUtils.Screen(fmt.Sprintf("%8.2fs  %s", stat.Duration/float32(stat.Runs), stat.Name))
+	}
+
+	sort.SliceStable(arr, func(i, j int) bool {
+		return arr[i].Fails > arr[j].Fails
+	})
+	logUtils.Screen("\n" + i118Utils.I118Prt.Sprintf("most_failing_cases"))
+	for idx, stat := range arr {
+		if idx >= historyTopCount || stat.Fails == 0 {
+			break
+		}
+		logUtils.PrintToCmd(fmt.Sprintf("%8s  %s", fmt.Sprintf("%d/%d", stat.Fails, stat.Runs), stat.Name), color.FgRed)
+	}
+}
+
+// Diff compares the cases of two runs, a run may be a history id, a log dir or a result.json file,
+// the last two runs in history are compared if not provided.
+func Diff(args []string) {
+	runs := make([]string, 0)
+	for _, arg := range args {
+		if len(runs) < 2 {
+			runs = append(runs, arg)
+		}
+	}
+
+	history := testingService.ListHistory()
+	for idx := len(history) - 1; idx >= 0 && len(runs) < 2; idx-- {
+		runs = append([]string{strconv.Itoa(history[idx].Id)}, runs...)
+	}
+	if len(runs) < 2 {
+		logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("no_history"), color.FgYellow)
+		return
+	}
+
+	reportA, err := testingService.GetHistoryReport(runs[0])
+	if err != nil {
+		logUtils.PrintToCmd(err.Error(), color.FgRed)
+		return
+	}
+	reportB, err := testingService.GetHistoryReport(runs[1])
+	if err != nil {
+		logUtils.PrintToCmd(err.Error(), color.FgRed)
+		return
+	}
+
+	keysA, casesA := testingService.GetHistoryCases(reportA)
+	keysB, casesB := testingService.GetHistoryCases(reportB)
+
+	newlyFailing := make([]string, 0)
+	newlyPassing := make([]string, 0)
+	added := make([]string, 0)
+	removed := make([]string, 0)
+	for _, key := range keysB {
+		csB := casesB[key]
+		csA, ok := casesA[key]
+		if !ok {
+			added = append(added, csB.Name)
+		} else if csA.Status != constant.FAIL.String() && csB.Status == constant.FAIL.String() {
+			newlyFailing = append(newlyFailing, csB.Name)
+		} else if csA.Status != constant.PASS.String() && csB.Status == constant.PASS.String() {
+			newlyPassing = append(newlyPassing, csB.Name)
+		}
+	}
+	for _, key := range keysA {
+		if _, ok := casesB[key]; !ok {
+			removed = append(removed, casesA[key].Name)
+		}
+	}
+
+	logUtils.Screen(i118Utils.I118Prt.Sprintf("diff_runs", runs[0], runs[1]))
+	if len(newlyFailing)+len(newlyPassing)+len(added)+len(removed) == 0 {
+		logUtils.Screen(i118Utils.I118Prt.Sprintf("diff_no_change"))
+		return
+	}
+
+	printDiffCases("diff_newly_failing", newlyFailing, color.FgRed)
+	printDiffCases("diff_newly_passing", newlyPassing, color.FgGreen)
+	printDiffCases("diff_added", added, color.FgCyan)
+	printDiffCases("diff_removed", removed, color.FgYellow)
+}
+
+func printDiffCases(title string, names []string, attr color.Attribute) {
+	if len(names) == 0 {
+		return
+	}
+
+	logUtils.Screen("\n" + i118Utils.I118Prt.Sprintf(title, len(names)))
+	for _, name := range names {
+		logUtils.PrintToCmd("  "+name, attr)
+	}
+}
@@ -0,0 +1,37 @@
+package action
+
+import (
+	testingService "github.com/easysoft/zentaoatf/src/service/testing"
+	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
+	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
+	logUtils "github.com/easysoft/zentaoatf/src/utils/log"
+	stdinUtils "github.com/easysoft/zentaoatf/src/utils/stdin"
+	"github.com/fatih/color"
+)
+
+func GenReport(files []string, format string) {
+	var resultDir string
+	if len(files) > 0 {
+		resultDir = files[0]
+	} else {
+		stdinUtils.InputForDir(&resultDir, "", "result")
+	}
+	resultDir = fileUtils.AddPathSepIfNeeded(resultDir)
+
+	if format == "" {
+		format = "html"
+	}
+
+	if format != "html" {
+		logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("report_format_not_supported", format), color.FgRed)
+		return
+	}
+
+	file, err := testingService.GenHtmlReport(resultDir)
+	if err != nil {
+		logUtils.PrintToCmd(err.Error(), color.FgRed)
+		return
+	}
+
+	logUtils.PrintTo(i118Utils.I118Prt.Sprintf("report_saved", file))
+}
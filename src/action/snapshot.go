@@ -0,0 +1,125 @@
+package action
+
+import (
+	"github.com/easysoft/zentaoatf/src/model"
+	constant "github.com/easysoft/zentaoatf/src/utils/const"
+	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
+	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
+	langUtils "github.com/easysoft/zentaoatf/src/utils/lang"
+	logUtils "github.com/easysoft/zentaoatf/src/utils/log"
+	scriptUtils "github.com/easysoft/zentaoatf/src/utils/script"
+	stdinUtils "github.com/easysoft/zentaoatf/src/utils/stdin"
+	stringUtils "github.com/easysoft/zentaoatf/src/utils/string"
+	zentaoUtils "github.com/easysoft/zentaoatf/src/utils/zentao"
+	"github.com/fatih/color"
+	"strings"
+)
+
+// UpdateSnapshots replaces the expects of failed steps with their actual output,
+// in script header or the independent .exp file where the expect comes from.
+func UpdateSnapshots(report model.TestReport, noNeedConfirm bool) {
+	for _, cs := range report.FuncResult {
+		if cs.Status != constant.FAIL.String() {
+			continue
+		}
+
+		if len(cs.SubResults) > 0 {
+			logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("snapshot_skip_data_driven", cs.Path), color.FgYellow)
+			continue
+		}
+
+		updateSnapshot(cs, noNeedConfirm)
+	}
+}
+
+func updateSnapshot(cs model.FuncResult, noNeedConfirm bool) {
+	file := cs.Path
+	content := fileUtils.ReadFile(file)
+
+	_, _, expectMap, isOldFormat := scriptUtils.GetStepAndExpectMap(file)
+	if isOldFormat {
+		logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("snapshot_old_format", file), color.FgYellow)
+		return
+	}
+
+	// if there is an independent .exp file, expects are read from it by position, the same as CheckCaseResult
+	expFile := zentaoUtils.GetDependentExpectFile(file)
+	expIndexes := map[string]int{}
+	if expFile != "" {
+		expectMap = scriptUtils.GetExpectMapFromIndependentFile(expectMap, fileUtils.ReadFile(expFile), false)
+
+		for idx, keyIfs := range expectMap.Keys() {
+			expIndexes[keyIfs.(string)] = idx
+		}
+	}
+
+	scriptExpects := map[string]string{}
+	expExpects := map[int]string{}
+	diffs := make([]string, 0)
+	for _, step := range cs.Steps {
+		if step.Status {
+			continue
+		}
+
+		stepId := strings.TrimRight(step.Id, ".")
+		if len(step.CheckPoints) != 1 {
+			logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("snapshot_skip_checkpoints", stepId, file, len(step.CheckPoints)), color.FgYellow)
+			continue
+		}
+
+		actual := step.CheckPoints[0].Actual
+		expectIfs, _ := expectMap.Get(step.Id)
+		expect, _ := expectIfs.(string)
+		expect = strings.TrimSpace(expect)
+		if expect == "" {
+			continue
+		}
+
+		if actual == "N/A" {
+			logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("snapshot_skip_no_actual", stepId, file), color.FgYellow)
+			continue
+		}
+		if !stringUtils.IsLiteralExpect(expect) {
+			logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("snapshot_skip_step", stepId, file), color.FgYellow)
+			continue
+		}
+
+		if idx, ok := expIndexes[step.Id]; ok {
+			expExpects[idx] = actual
+		} else {
+			scriptExpects[step.Id] = actual
+		}
+
+		diffs = append(diffs, step.Id, "- "+expect, "+ "+actual)
+	}
+
+	if len(diffs) == 0 {
+		return
+	}
+
+	logUtils.PrintToCmd("\n"+i118Utils.I118Prt.Sprintf("snapshot_diff", file), color.FgCyan)
+	for i := 0; i < len(diffs); i += 3 {
+		logUtils.PrintTo("Step " + strings.TrimRight(diffs[i], "."))
+		logUtils.PrintToCmd(diffs[i+1], color.FgRed)
+		logUtils.PrintToCmd(diffs[i+2], color.FgGreen)
+	}
+
+	if !noNeedConfirm {
+		yes := true
+		stdinUtils.InputForBool(&yes, true, "confirm_update_snapshots")
+		if !yes {
+			return
+		}
+	}
+
+	if len(scriptExpects) > 0 {
+		lang := langUtils.GetLangByFile(file)
+		fileUtils.WriteFile(file, scriptUtils.ReplaceExpectsInScript(content, lang, scriptExpects))
+		logUtils.PrintTo(i118Utils.I118Prt.Sprintf("snapshot_updated", file))
+	}
+	if len(expExpects) > 0 {
+		expContent := fileUtils.ReadFile(expFile)
+		fileUtils.WriteFile(expFile, scriptUtils.ReplaceExpectsInExpFile(expContent, expExpects))
+		logUtils.PrintTo(i118Utils.I118Prt.Sprintf("snapshot_updated", expFile))
+	}
+}
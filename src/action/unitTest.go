@@ -1,18 +1,37 @@
 package action
 
 import (
+	"github.com/easysoft/zentaoatf/src/model"
 	testingService "github.com/easysoft/zentaoatf/src/service/testing"
 	zentaoService "github.com/easysoft/zentaoatf/src/service/zentao"
+	constant "github.com/easysoft/zentaoatf/src/utils/const"
 	shellUtils "github.com/easysoft/zentaoatf/src/utils/shell"
+	"github.com/easysoft/zentaoatf/src/utils/vari"
 	time2 "time"
 )
 
 func RunUnitTest(cmdStr string) string {
+	var testSuites []model.UnitTestSuite
+	var resultDir string
+
+	if vari.UnitTestTool == "" {
+		vari.UnitTestTool = testingService.GetUnitTestTool(cmdStr)
+	}
+
 	startTime := time2.Now().Unix()
-	shellUtils.ExeAppWithOutput(cmdStr)
+	if vari.UnitTestType == constant.UnitTestTypeGoTest {
+		output := shellUtils.ExeAppWithOutputFilter(testingService.GetGoTestCmd(cmdStr), testingService.GetGoTestOutput)
+		testSuites, resultDir = testingService.RetrieveGoTestResult(output)
+	} else if vari.UnitTestType == constant.UnitTestTypeTap {
+		output := shellUtils.ExeAppWithOutput(cmdStr)
+		testSuites, resultDir = testingService.RetrieveTapResult(output)
+	} else {
+		cmdStr, resultDir = testingService.PrepareUnitTest(cmdStr)
+		shellUtils.ExeAppWithOutput(cmdStr)
+		testSuites = testingService.RetrieveUnitResult(resultDir)
+	}
 	endTime := time2.Now().Unix()
 
-	testSuites, resultDir := testingService.RetrieveUnitResult()
 	cases, classNameMaxWidth, time := testingService.ParserUnitTestResult(testSuites)
 
 	if time == 0 {
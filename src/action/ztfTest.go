@@ -11,14 +11,16 @@ import (
 	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
 	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
 	logUtils "github.com/easysoft/zentaoatf/src/utils/log"
+	stringUtils "github.com/easysoft/zentaoatf/src/utils/string"
 	"github.com/easysoft/zentaoatf/src/utils/vari"
 	zentaoUtils "github.com/easysoft/zentaoatf/src/utils/zentao"
 	"github.com/mattn/go-runewidth"
 	"path"
 	"strconv"
+	"strings"
 )
 
-func RunZTFTest(files []string, suiteIdStr, taskIdStr string) error {
+func RunZTFTest(files []string, suiteIdStr, taskIdStr string, noNeedConfirm bool) error {
 	logUtils.InitLogger()
 
 	cases := make([]string, 0)
@@ -63,12 +65,18 @@ func RunZTFTest(files []string, suiteIdStr, taskIdStr string) error {
 		}
 	}
 
+	cases = filterCasesByMeta(cases)
+
 	if len(cases) < 1 {
 		logUtils.PrintTo("\n" + i118Utils.I118Prt.Sprintf("no_cases"))
 		return nil
 	}
 
-	runCases(cases)
+	report := runCases(cases)
+
+	if vari.UpdateSnapshots {
+		UpdateSnapshots(report, noNeedConfirm)
+	}
 
 	return nil
 }
@@ -111,7 +119,7 @@ func getCaseBySuiteFile(file string, dir string) []string {
 	return cases
 }
 
-func runCases(cases []string) {
+func runCases(cases []string) model.TestReport {
 	casesToRun, casesToIgnore := filterCases(cases)
 
 	var report = model.TestReport{Env: commonUtils.GetOs(),
@@ -136,6 +144,36 @@ func runCases(cases []string) {
 
 	testingService.ExeScripts(casesToRun, casesToIgnore, &report, pathMaxWidth, numbMaxWidth)
 	testingService.GenZTFTestReport(report, pathMaxWidth)
+
+	return report
+}
+
+// filter by tags, priority and owner in script header
+func filterCasesByMeta(cases []string) (ret []string) {
+	if vari.Tags == "" && vari.Priority == "" && vari.Owner == "" {
+		return cases
+	}
+
+	priorities := strings.Split(vari.Priority, ",")
+	owners := strings.Split(vari.Owner, ",")
+
+	for _, cs := range cases {
+		tags, priority, owner := zentaoUtils.GetCaseMeta(cs)
+
+		if vari.Tags != "" && !zentaoUtils.MatchCaseTags(tags, vari.Tags) {
+			continue
+		}
+		if vari.Priority != "" && !stringUtils.FindInArr(strconv.Itoa(priority), priorities) {
+			continue
+		}
+		if vari.Owner != "" && !stringUtils.FindInArr(owner, owners) {
+			continue
+		}
+
+		ret = append(ret, cs)
+	}
+
+	return
 }
 
 func isRunWithSuiteFile(files []string) (suiteFile, dir string) {
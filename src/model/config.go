@@ -8,6 +8,11 @@ type Config struct {
 	Account  string
 	Password string
 
+	RequestTimeout int // seconds of a request to zentao
+	RequestRetry   int // times to retry a failed request, -1 to disable
+
+	UnitCaseIdRegx string // regexp to read zentao case id from name of unit test, like zentao(\d+)
+
 	Javascript string
 	Lua        string
 	Perl       string
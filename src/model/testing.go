@@ -1,6 +1,9 @@
 package model
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"time"
+)
 
 type Product struct {
 	Id   string
@@ -110,6 +113,8 @@ type Bug struct {
 	Uid         string `json:"uid"`
 	CaseVersion string `json:"caseVersion"`
 	OldTaskID   string `json:"oldTaskID"`
+
+	Files []Attachment `json:"files,omitempty"`
 }
 
 type TestReport struct {
@@ -125,6 +130,7 @@ type TestReport struct {
 	Pass      int   `json:"pass"`
 	Fail      int   `json:"fail"`
 	Skip      int   `json:"skip"`
+	Error     int   `json:"error"`
 	Total     int   `json:"total"`
 	StartTime int64 `json:"startTime"`
 	EndTime   int64 `json:"endTime"`
@@ -134,6 +140,20 @@ type TestReport struct {
 	UnitResult []UnitResult `json:"unitResult"`
 }
 
+type HistoryRun struct {
+	Id        int    `json:"id"`
+	Time      int64  `json:"time"`
+	LogDir    string `json:"logDir"`
+	TestType  string `json:"testType"`
+	TestFrame string `json:"testFrame"`
+
+	Pass     int   `json:"pass"`
+	Fail     int   `json:"fail"`
+	Skip     int   `json:"skip"`
+	Total    int   `json:"total"`
+	Duration int64 `json:"duration"`
+}
+
 type FuncResult struct {
 	Id        int    `json:"id"`
 	ProductId int    `json:"productId"`
@@ -141,7 +161,22 @@ type FuncResult struct {
 	Status    string `json:"status"`
 	Title     string `json:"title"`
 
-	Steps []StepLog `json:"steps"`
+	Tags     []string `json:"tags,omitempty"`
+	Priority int      `json:"priority,omitempty"`
+	Owner    string   `json:"owner,omitempty"`
+
+	Steps    []StepLog `json:"steps"`
+	Duration float32   `json:"duration,omitempty"`
+
+	Attempts []FuncAttempt `json:"attempts,omitempty"` // all runs of a retried case
+	Flaky    bool          `json:"flaky,omitempty"`    // passed after failing
+
+	Row        map[string]string `json:"row,omitempty"`        // data of the row for a data-driven run
+	SubResults []FuncResult      `json:"subResults,omitempty"` // one for each data row
+}
+type FuncAttempt struct {
+	Status string    `json:"status"`
+	Steps  []StepLog `json:"steps"`
 }
 type StepLog struct {
 	Id     string `json:"id"`
@@ -161,10 +196,14 @@ type CheckPointLog struct {
 type UnitTestSuite struct {
 	XMLName xml.Name `xml:"testsuite"`
 
-	Name     string
+	Name     string  `xml:"name,attr,omitempty"`
 	Duration int64   `xml:"-"`
 	Time     float32 `xml:"time,attr"`
 
+	Tests    int `xml:"tests,attr,omitempty"`
+	Failures int `xml:"failures,attr,omitempty"`
+	Skipped  int `xml:"skipped,attr,omitempty"`
+
 	Properties Properties   `xml:"properties"`
 	TestCases  []UnitResult `xml:"testcase"`
 }
@@ -172,19 +211,43 @@ type UnitResult struct {
 	Title     string `json:"title" xml:"name,attr"`
 	TestSuite string `json:"testSuite" xml:"classname,attr"`
 
-	StartTime int64 `json:"startTime" xml:"startTime"`
-	EndTime   int64 `json:"endTime" xml:"endTime"`
+	StartTime int64 `json:"startTime" xml:"startTime,omitempty"`
+	EndTime   int64 `json:"endTime" xml:"endTime,omitempty"`
 
 	Duration float32  `json:"duration" xml:"time,attr"`
 	Failure  *Failure `json:"failure" xml:"failure,omitempty"`
+	Error    *Failure `json:"error,omitempty" xml:"error,omitempty"` // error in test setup, not in the product
+	Skipped  *Skipped `json:"skipped,omitempty" xml:"skipped,omitempty"`
 
-	Id     int    `json:"id"`
-	Status string `json:"status"`
+	SystemOut   string       `json:"systemOut,omitempty" xml:"system-out,omitempty"`
+	SystemErr   string       `json:"systemErr,omitempty" xml:"system-err,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty" xml:"-"`
+
+	Properties *Properties `json:"-" xml:"properties,omitempty"`
+	Tags       []string    `json:"tags,omitempty" xml:"-"`
+	Steps      []StepLog   `json:"steps,omitempty" xml:"-"` // keywords of robot test
+
+	Id     int    `json:"id" xml:"-"`
+	Cid    int    `json:"cid,omitempty" xml:"-"` // case id in zentao
+	Status string `json:"status" xml:"-"`
 }
 
 type Failure struct {
-	Type string `json:"type" xml:"type,attr"`
-	Desc string `json:"desc" xml:",innerxml"`
+	Type    string `json:"type" xml:"type,attr"`
+	Message string `json:"message,omitempty" xml:"message,attr,omitempty"`
+	Desc    string `json:"desc" xml:",innerxml"`
+}
+
+// file referred by a unit test, such as screenshot, content is base64 encoded when uploading
+type Attachment struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Content string `json:"content,omitempty"`
+}
+
+type Skipped struct {
+	Message string `json:"message" xml:"message,attr,omitempty"`
 }
 
 type Properties struct {
@@ -228,6 +291,12 @@ type PyTestSuites struct {
 				Text    string `xml:",chardata"`
 				Message string `xml:"message,attr"`
 			} `xml:"error"`
+			Skipped *struct {
+				Text    string `xml:",chardata"`
+				Message string `xml:"message,attr"`
+			} `xml:"skipped"`
+			SystemOut string `xml:"system-out"`
+			SystemErr string `xml:"system-err"`
 
 			Status string
 		} `xml:"testcase"`
@@ -268,7 +337,11 @@ type GTestSuites struct {
 				Desc string `xml:",innerxml"`
 			} `xml:"failure,omitempty"`
 
-			Status string `xml:"status,attr"`
+			Status  string `xml:"status,attr"`
+			Result  string `xml:"result,attr"`
+			Skipped *struct {
+				Message string `xml:"message,attr"`
+			} `xml:"skipped"`
 		} `xml:"testcase"`
 
 		Duration int
@@ -372,31 +445,37 @@ type RobotSuite struct {
 }
 
 type RobotTest struct {
-	Text string `xml:",chardata"`
-	ID   string `xml:"id,attr"`
-	Name string `xml:"name,attr"`
-	Kw   []struct {
-		Text      string `xml:",chardata"`
-		Name      string `xml:"name,attr"`
-		Library   string `xml:"library,attr"`
-		Doc       string `xml:"doc"`
-		Arguments struct {
-			Text string   `xml:",chardata"`
-			Arg  []string `xml:"arg"`
-		} `xml:"arguments"`
-		Msg struct {
-			Text      string `xml:",chardata"`
-			Timestamp string `xml:"timestamp,attr"`
-			Level     string `xml:"level,attr"`
-		} `xml:"msg"`
-		Status RobotStatus `xml:"status"`
-		Assign struct {
-			Text string `xml:",chardata"`
-			Var  string `xml:"var"`
-		} `xml:"assign"`
-	} `xml:"kw"`
-	Doc    string      `xml:"doc"`
-	Status RobotStatus `xml:"status"`
+	Text     string         `xml:",chardata"`
+	ID       string         `xml:"id,attr"`
+	Name     string         `xml:"name,attr"`
+	Keywords []RobotKeyword `xml:",any"` // kw, and for, if or try since robot 4
+	Doc      string         `xml:"doc"`
+	Tags     []string       `xml:"tag"`
+	OldTags  []string       `xml:"tags>tag"` // before robot 4
+	Status   RobotStatus    `xml:"status"`
+}
+
+// keyword or control structure like for and iter, children are kept in order
+type RobotKeyword struct {
+	XMLName  xml.Name
+	Name     string         `xml:"name,attr"`
+	Library  string         `xml:"library,attr"`
+	Owner    string         `xml:"owner,attr"` // library since robot 7
+	Type     string         `xml:"type,attr"`
+	Doc      string         `xml:"doc"`
+	Args     []string       `xml:"arg"`
+	OldArgs  []string       `xml:"arguments>arg"` // before robot 4
+	Vars     []string       `xml:"var"`
+	Msgs     []RobotMsg     `xml:"msg"`
+	Tags     []string       `xml:"tag"`
+	Status   RobotStatus    `xml:"status"`
+	Keywords []RobotKeyword `xml:",any"`
+}
+
+type RobotMsg struct {
+	Text      string `xml:",chardata"`
+	Timestamp string `xml:"timestamp,attr"`
+	Level     string `xml:"level,attr"`
 }
 
 type RobotStatus struct {
@@ -404,6 +483,8 @@ type RobotStatus struct {
 	Status    string `xml:"status,attr"`
 	StartTime string `xml:"starttime,attr"`
 	EndTime   string `xml:"endtime,attr"`
+	Start     string `xml:"start,attr"` // since robot 7
+	Elapsed   string `xml:"elapsed,attr"`
 }
 
 // cypress
@@ -436,6 +517,9 @@ type CypressTestcase struct {
 	Time      float64          `xml:"time,attr"`
 	Classname string           `xml:"classname,attr"`
 	Failures  []CypressFailure `xml:"failure"`
+	Skipped   *struct{}        `xml:"skipped"`
+	SystemOut string           `xml:"system-out"`
+	SystemErr string           `xml:"system-err"`
 }
 
 type CypressFailure struct {
@@ -443,3 +527,127 @@ type CypressFailure struct {
 	Message string `xml:"message,attr"`
 	Type    string `xml:"type,attr"`
 }
+
+// go test -json
+type GoTestEvent struct {
+	Time    time.Time `json:"Time"`
+	Action  string    `json:"Action"`
+	Package string    `json:"Package"`
+	Test    string    `json:"Test"`
+	Elapsed float64   `json:"Elapsed"`
+	Output  string    `json:"Output"`
+}
+
+// nunit3 xml
+type NUnitTestRun struct {
+	XMLName    xml.Name         `xml:"test-run"`
+	Duration   float32          `xml:"duration,attr"`
+	TestSuites []NUnitTestSuite `xml:"test-suite"`
+}
+
+type NUnitTestSuite struct {
+	Type       string           `xml:"type,attr"`
+	Name       string           `xml:"name,attr"`
+	FullName   string           `xml:"fullname,attr"`
+	TestSuites []NUnitTestSuite `xml:"test-suite"`
+	TestCases  []NUnitTestCase  `xml:"test-case"`
+}
+
+type NUnitTestCase struct {
+	Name      string  `xml:"name,attr"`
+	FullName  string  `xml:"fullname,attr"`
+	ClassName string  `xml:"classname,attr"`
+	Result    string  `xml:"result,attr"`
+	Label     string  `xml:"label,attr"`
+	StartTime string  `xml:"start-time,attr"`
+	EndTime   string  `xml:"end-time,attr"`
+	Duration  float32 `xml:"duration,attr"`
+
+	Properties Properties `xml:"properties"`
+	Failure    *struct {
+		Message    string `xml:"message"`
+		StackTrace string `xml:"stack-trace"`
+	} `xml:"failure"`
+	Reason *struct {
+		Message string `xml:"message"`
+	} `xml:"reason"`
+	Output      string `xml:"output"`
+	Attachments []struct {
+		FilePath string `xml:"filePath"`
+	} `xml:"attachments>attachment"`
+}
+
+// xunit v2 xml
+type XUnitAssemblies struct {
+	XMLName    xml.Name        `xml:"assemblies"`
+	Assemblies []XUnitAssembly `xml:"assembly"`
+}
+
+type XUnitAssembly struct {
+	Name        string            `xml:"name,attr"`
+	Time        float32           `xml:"time,attr"`
+	Errors      []XUnitError      `xml:"errors>error"`
+	Collections []XUnitCollection `xml:"collection"`
+}
+
+type XUnitError struct {
+	Type    string        `xml:"type,attr"`
+	Name    string        `xml:"name,attr"`
+	Failure *XUnitFailure `xml:"failure"`
+}
+
+type XUnitCollection struct {
+	Name  string      `xml:"name,attr"`
+	Tests []XUnitTest `xml:"test"`
+}
+
+type XUnitTest struct {
+	Name   string  `xml:"name,attr"`
+	Type   string  `xml:"type,attr"`
+	Method string  `xml:"method,attr"`
+	Time   float32 `xml:"time,attr"`
+	Result string  `xml:"result,attr"`
+
+	Failure *XUnitFailure `xml:"failure"`
+	Reason  string        `xml:"reason"`
+	Output  string        `xml:"output"`
+	Traits  []Property    `xml:"traits>trait"`
+}
+
+type XUnitFailure struct {
+	ExceptionType string `xml:"exception-type,attr"`
+	Message       string `xml:"message"`
+	StackTrace    string `xml:"stack-trace"`
+}
+
+// visual studio trx, produced by dotnet test --logger trx for mstest, nunit and xunit
+type TrxTestRun struct {
+	XMLName xml.Name `xml:"TestRun"`
+	Results []struct {
+		TestId    string `xml:"testId,attr"`
+		TestName  string `xml:"testName,attr"`
+		Duration  string `xml:"duration,attr"`
+		StartTime string `xml:"startTime,attr"`
+		EndTime   string `xml:"endTime,attr"`
+		Outcome   string `xml:"outcome,attr"`
+
+		StdOut    string `xml:"Output>StdOut"`
+		StdErr    string `xml:"Output>StdErr"`
+		ErrorInfo *struct {
+			Message    string `xml:"Message"`
+			StackTrace string `xml:"StackTrace"`
+		} `xml:"Output>ErrorInfo"`
+	} `xml:"Results>UnitTestResult"`
+	Definitions []struct {
+		Id         string `xml:"id,attr"`
+		Name       string `xml:"name,attr"`
+		Properties []struct {
+			Key   string `xml:"Key"`
+			Value string `xml:"Value"`
+		} `xml:"Properties>Property"`
+		Method struct {
+			ClassName string `xml:"className,attr"`
+			Name      string `xml:"name,attr"`
+		} `xml:"TestMethod"`
+	} `xml:"TestDefinitions>UnitTest"`
+}
@@ -22,3 +22,59 @@ type Option struct {
 	Code string
 	Name string
 }
+
+// objects of restful api v1
+
+type RestCaseList struct {
+	Page      int
+	Total     int
+	Limit     int
+	Testcases []RestCase
+}
+
+type RestCase struct {
+	Id      int
+	Product int
+	Module  int
+	Title   string
+	Steps   []RestStep
+}
+
+type RestModuleList struct {
+	Modules []RestModule
+}
+
+type RestModule struct {
+	Id       int
+	Name     string
+	Parent   int
+	Children []RestModule
+}
+
+type RestStep struct {
+	Id     int
+	Parent int
+	Type   string
+	Desc   string
+	Expect string
+}
+
+type RestSuite struct {
+	Id        int
+	Name      string
+	Testcases []RestCase
+}
+
+type RestTask struct {
+	Id        int
+	Name      string
+	Testcases []RestRun
+}
+
+type RestRun struct {
+	Id      int // runId in task
+	Case    int // real caseId
+	Product int
+	Module  int
+	Title   string
+}
@@ -11,6 +11,7 @@ type Build struct {
 	SuiteId   string   `json:"suiteId,omitempty"`
 	TaskId    string   `json:"taskId,omitempty"`
 	Files     []string `json:"files,omitempty"`
+	Timeout   int      `json:"timeout,omitempty"`
 
 	UnitTestType string `json:"unitTestType,omitempty"`
 	UnitTestTool string `json:"unitTestTool,omitempty"`
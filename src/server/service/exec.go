@@ -4,9 +4,9 @@ import (
 	"github.com/easysoft/zentaoatf/src/action"
 	"github.com/easysoft/zentaoatf/src/server/domain"
 	serverUtils "github.com/easysoft/zentaoatf/src/server/utils/common"
+	testingService "github.com/easysoft/zentaoatf/src/service/testing"
 	constant "github.com/easysoft/zentaoatf/src/utils/const"
 	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
-	stringUtils "github.com/easysoft/zentaoatf/src/utils/string"
 	"github.com/easysoft/zentaoatf/src/utils/vari"
 	"strings"
 )
@@ -28,7 +28,7 @@ func (s *ExecService) Exec(build domain.Build) (reply domain.OptResult) {
 	s.prepareDir(&build)
 
 	resultDir := ""
-	if stringUtils.FindInArr(build.UnitTestType, constant.UnitTestTypes) { // unit test
+	if testingService.IsUnitTestType(build.UnitTestType) { // unit test
 		vari.ProductId = build.ProductId
 
 		vari.UnitTestType = build.UnitTestType
@@ -38,8 +38,9 @@ func (s *ExecService) Exec(build domain.Build) (reply domain.OptResult) {
 
 	} else { // ztf functional test
 		vari.ProductId = build.ProductId
+		vari.Timeout = build.Timeout
 
-		action.RunZTFTest(build.Files, build.SuiteId, build.TaskId)
+		action.RunZTFTest(build.Files, build.SuiteId, build.TaskId, true)
 		resultDir = vari.LogDir
 	}
 
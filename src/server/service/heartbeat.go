@@ -40,11 +40,11 @@ func (s *HeartBeatService) HeartBeat(isBusy bool) {
 	}
 	data["status"] = status
 
-	_, ok := client.PostObject(url, data, false)
-	if ok {
+	_, err := client.PostObject(url, data, false)
+	if err == nil {
 		logUtils.PrintTo(i118Utils.I118Prt.Sprintf("success_heart_beat"))
 	} else {
-		logUtils.PrintTo(i118Utils.I118Prt.Sprintf("fail_heart_beat"))
+		logUtils.PrintTo(i118Utils.I118Prt.Sprintf("fail_heart_beat") + " " + client.GetErrorMsg(err))
 	}
 
 	return
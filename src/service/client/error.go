@@ -0,0 +1,137 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/bitly/go-simplejson"
+	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
+)
+
+// ZentaoError is returned when a request to zentao fails,
+// StatusCode is 0 if there is no response, such as connection refused or timeout.
+type ZentaoError struct {
+	Method     string
+	Url        string // without query, which may have session id
+	StatusCode int
+	Message    string // message returned by zentao, or the error of connection
+	Timeout    bool
+	Html       bool // a html page is returned instead of json, such as the login page
+	Login      bool // failed to login
+	Retried    int
+}
+
+func newZentaoError(method string, rawUrl string, statusCode int, message string) *ZentaoError {
+	if u, err := url.Parse(rawUrl); err == nil {
+		rawUrl = u.Scheme + "://" + u.Host + u.Path
+	}
+
+	return &ZentaoError{Method: method, Url: rawUrl, StatusCode: statusCode, Message: message}
+}
+
+func (e *ZentaoError) Error() string {
+	msg := e.Message
+	if e.StatusCode > 0 {
+		msg = strings.TrimSuffix(e.Status()+", "+msg, ", ")
+	}
+	return fmt.Sprintf("%s %s: %s", e.Method, e.Url, msg)
+}
+
+// Status is like 502 Bad Gateway
+func (e *ZentaoError) Status() string {
+	return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// temporary errors are worth retrying, such as a 502 from proxy when zentao is restarting
+func (e *ZentaoError) temporary() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// GetErrorMsg returns the message telling users what's wrong and what to do
+func GetErrorMsg(err error) string {
+	e, ok := err.(*ZentaoError)
+	if !ok {
+		return err.Error()
+	}
+
+	msg := strings.TrimRight(e.Message, "。. ") // ends with period in the template
+
+	switch {
+	case e.Timeout:
+		return i118Utils.I118Prt.Sprintf("zentao_request_timeout", e.Url, getRequestTimeout(), e.Retried)
+	case e.Html:
+		return i118Utils.I118Prt.Sprintf("zentao_html_returned", e.Url)
+	case e.StatusCode == 0:
+		return i118Utils.I118Prt.Sprintf("zentao_connect_fail", e.Url, msg)
+	case e.Login || e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		if msg == "" {
+			msg = e.Status()
+		}
+		return i118Utils.I118Prt.Sprintf("zentao_auth_fail", msg)
+	case e.StatusCode == http.StatusNotFound:
+		return i118Utils.I118Prt.Sprintf("zentao_not_found", e.Status(), e.Url)
+	case e.StatusCode >= 500:
+		if msg != "" {
+			msg = ": " + msg
+		}
+		return i118Utils.I118Prt.Sprintf("zentao_server_error", e.Status(), e.Url, e.Retried, msg)
+	default:
+		if msg == "" {
+			msg = e.Status()
+		}
+		return i118Utils.I118Prt.Sprintf("zentao_request_fail", e.Url, msg)
+	}
+}
+
+// getZentaoMessage reads the message from response, like {"status": "failed", "reason": "..."} of json views,
+// {"result": "fail", "message": {"title": ["..."]}} of forms and {"error": "..."} of restful api.
+func getZentaoMessage(body []byte) string {
+	json, err := simplejson.NewJson(body)
+	if err != nil {
+		str := strings.TrimSpace(string(body))
+		if strings.Index(str, "<html") > -1 {
+			return ""
+		}
+		if len(str) > 200 {
+			str = str[:200] + "..."
+		}
+		return str
+	}
+
+	if data, err := json.Get("data").String(); err == nil && data != "" { // data is a json string in json views
+		if msg := getZentaoMessage([]byte(data)); msg != "" {
+			return msg
+		}
+	}
+
+	for _, key := range []string{"message", "reason", "error"} {
+		val, ok := json.CheckGet(key)
+		if !ok {
+			continue
+		}
+
+		if str, err := val.String(); err == nil {
+			return str
+		}
+
+		// messages of fields
+		msgs := make([]string, 0)
+		mp, _ := val.Map()
+		for field, item := range mp {
+			msgs = append(msgs, fmt.Sprintf("%s %v", field, item))
+		}
+		sort.Strings(msgs)
+		if len(msgs) > 0 {
+			return strings.Join(msgs, "; ")
+		}
+	}
+
+	return ""
+}
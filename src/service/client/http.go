@@ -2,7 +2,6 @@ package client
 
 import (
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/cookiejar"
@@ -21,6 +20,7 @@ import (
 
 var gCurCookieJar *cookiejar.Jar
 var client *http.Client
+var postClient *http.Client // posts are sent without the cookie jar
 var zentao_cookies string
 
 func init() {
@@ -28,9 +28,10 @@ func init() {
 	client = &http.Client{
 		Jar: gCurCookieJar,
 	}
+	postClient = &http.Client{}
 }
 
-func Get(url string) (string, bool) {
+func Get(url string) (string, error) {
 	// client := &http.Client{}
 	if vari.RequestType == constant.RequestTypePathInfo {
 		url = url + "?" + vari.SessionVar + "=" + vari.SessionId
@@ -42,63 +43,30 @@ func Get(url string) (string, bool) {
 		logUtils.Screen(i118Utils.I118Prt.Sprintf("server_address") + url)
 	}
 
-	req, reqErr := http.NewRequest("GET", url, nil)
-	if reqErr != nil {
-		if vari.Verbose {
-			logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("server_return")+reqErr.Error(), color.FgRed)
+	bodyStr, err := sendRequest(client, func() (*http.Request, error) {
+		req, reqErr := http.NewRequest("GET", url, nil)
+		if reqErr == nil {
+			req = set_cookies(req) // 设置cookies
 		}
-		return "", false
-	}
-
-	req = set_cookies(req) // 设置cookies
-
-	resp, respErr := client.Do(req)
-	if respErr != nil {
-		if vari.Verbose {
-			logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("server_return")+respErr.Error(), color.FgRed)
-		}
-		return "", false
-	}
-
-	// cookies := resp.Cookies() //遍历cookies
-	// for _, cookie := range cookies {
-	// 	fmt.Println("cookie:", cookie)
-	// }
-
-	bodyStr, _ := ioutil.ReadAll(resp.Body)
-	if vari.Verbose {
-		logUtils.Screen(i118Utils.I118Prt.Sprintf("server_return") + logUtils.ConvertUnicode(bodyStr))
+		return req, reqErr
+	}, retryIdempotent)
+	if err != nil {
+		return "", err
 	}
 
-	var bodyJson model.ZentaoResponse
-	jsonErr := json.Unmarshal(bodyStr, &bodyJson)
-	if jsonErr != nil {
-		if strings.Index(string(bodyStr), "<html>") > -1 {
-			if vari.Verbose {
-				logUtils.Screen(i118Utils.I118Prt.Sprintf("server_return") + " HTML - " +
-					gohtml.FormatWithLineNo(string(bodyStr)))
-			}
-			return "", false
-		} else {
-			if vari.Verbose {
-				logUtils.PrintToCmd(jsonErr.Error(), color.FgRed)
-			}
-			return "", false
-		}
-	}
+	return getZentaoData("GET", url, bodyStr, false)
+}
 
-	defer resp.Body.Close()
+func PostObject(url string, params interface{}, useFormFormat bool) (string, error) {
+	return postObject(url, params, useFormFormat, retryNotSent)
+}
 
-	status := bodyJson.Status
-	if status == "" { // 非嵌套结构
-		return string(bodyStr), true
-	} else { // 嵌套结构
-		dataStr := bodyJson.Data
-		return dataStr, status == "success"
-	}
+// PostResultObject posts the result of a run, it's sent again if zentao fails to handle it
+func PostResultObject(url string, params interface{}) (string, error) {
+	return postObject(url, params, false, retryNotHandled)
 }
 
-func PostObject(url string, params interface{}, useFormFormat bool) (string, bool) {
+func postObject(url string, params interface{}, useFormFormat bool, policy retryPolicy) (string, error) {
 	if vari.RequestType == constant.RequestTypePathInfo {
 		url = url + "?" + vari.SessionVar + "=" + vari.SessionId
 	} else {
@@ -112,8 +80,6 @@ func PostObject(url string, params interface{}, useFormFormat bool) (string, boo
 		logUtils.Screen(i118Utils.I118Prt.Sprintf("server_params") + string(jsonStr))
 	}
 
-	client := &http.Client{}
-
 	val := string(jsonStr)
 	if useFormFormat {
 		val, _ = form.EncodeToString(params)
@@ -122,63 +88,25 @@ func PostObject(url string, params interface{}, useFormFormat bool) (string, boo
 		val = re3.ReplaceAllStringFunc(string(val), replacePostData)
 	}
 
-	req, reqErr := http.NewRequest("POST", url, strings.NewReader(val))
-	if reqErr != nil {
-		if vari.Verbose {
-			logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("server_return")+reqErr.Error(), color.FgRed)
-		}
-		return "", false
-	}
-
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req = set_cookies(req) // 设置cookies
-
-	resp, respErr := client.Do(req)
-	if respErr != nil {
-		if vari.Verbose {
-			logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("server_return")+respErr.Error(), color.FgRed)
-		}
-		return "", false
-	}
-
-	bodyStr, _ := ioutil.ReadAll(resp.Body)
-	if vari.Verbose {
-		logUtils.Screen(i118Utils.I118Prt.Sprintf("server_return") + logUtils.ConvertUnicode(bodyStr))
-	}
-
-	var bodyJson model.ZentaoResponse
-	jsonErr := json.Unmarshal(bodyStr, &bodyJson)
-	if jsonErr != nil {
-		if strings.Index(string(bodyStr), "<html>") > -1 { // some api return a html
-			if vari.Verbose {
-				logUtils.Screen(i118Utils.I118Prt.Sprintf("server_return") + " HTML - " +
-					gohtml.FormatWithLineNo(string(bodyStr)))
-			}
-			return string(bodyStr), true
-		} else {
-			if vari.Verbose {
-				logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("server_return")+jsonErr.Error(), color.FgRed)
-			}
-			return "", false
+	bodyStr, err := sendRequest(postClient, func() (*http.Request, error) {
+		req, reqErr := http.NewRequest("POST", url, strings.NewReader(val))
+		if reqErr == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req = set_cookies(req) // 设置cookies
 		}
+		return req, reqErr
+	}, policy)
+	if err != nil {
+		return "", err
 	}
 
-	defer resp.Body.Close()
-
-	status := bodyJson.Status
-	if status == "" { // 非嵌套结构
-		return string(bodyStr), true
-	} else { // 嵌套结构
-		dataStr := bodyJson.Data
-		return dataStr, status == "success"
-	}
+	return getZentaoData("POST", url, bodyStr, true) // some api return a html
 }
 
-func PostStr(url string, params map[string]string) (msg string, ok bool) {
+func PostStr(url string, params map[string]string) (string, error) {
 	if vari.Verbose {
 		logUtils.Screen(i118Utils.I118Prt.Sprintf("server_address") + url)
 	}
-	client := &http.Client{}
 
 	paramStr := ""
 	idx := 0
@@ -190,60 +118,56 @@ func PostStr(url string, params map[string]string) (msg string, ok bool) {
 		idx++
 	}
 
-	req, reqErr := http.NewRequest("POST", url, strings.NewReader(paramStr))
-	if reqErr != nil {
-		if vari.Verbose {
-			logUtils.PrintToCmd(reqErr.Error(), color.FgRed)
-		}
-		ok = false
-		return
-	}
-
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("cookie", vari.SessionVar+"="+vari.SessionId)
-	req = set_cookies(req) // 设置cookies
-	resp, respErr := client.Do(req)
-
-	cookies := resp.Cookies() //遍历cookies
-	for _, cookie := range cookies {
-		fmt.Println("cookie:", cookie)
-	}
-
-	if respErr != nil {
-		if vari.Verbose {
-			logUtils.PrintToCmd(respErr.Error(), color.FgRed)
+	bodyStr, err := sendRequest(postClient, func() (*http.Request, error) {
+		req, reqErr := http.NewRequest("POST", url, strings.NewReader(paramStr))
+		if reqErr == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("cookie", vari.SessionVar+"="+vari.SessionId)
+			req = set_cookies(req) // 设置cookies
 		}
-		ok = false
-		return
+		return req, reqErr
+	}, retryNotSent)
+	if err != nil {
+		return "", err
 	}
 
-	bodyStr, _ := ioutil.ReadAll(resp.Body)
-	if vari.Verbose {
-		logUtils.Screen(i118Utils.I118Prt.Sprintf("server_return") + logUtils.ConvertUnicode(bodyStr))
-	}
+	return getZentaoData("POST", url, bodyStr, false)
+}
 
+// getZentaoData returns data of json views, which is wrapped like {"status": "success", "data": "..."},
+// the whole body is returned if it's not wrapped.
+func getZentaoData(method string, url string, bodyStr []byte, htmlIsOk bool) (string, error) {
 	var bodyJson model.ZentaoResponse
 	jsonErr := json.Unmarshal(bodyStr, &bodyJson)
 	if jsonErr != nil {
-		if vari.Verbose {
-			if strings.Index(url, "login") == -1 { // jsonErr caused by login request return a html
-				logUtils.PrintToCmd(jsonErr.Error(), color.FgRed)
+		zentaoErr := newZentaoError(method, url, http.StatusOK, getZentaoMessage(bodyStr))
+
+		if strings.Index(string(bodyStr), "<html>") > -1 {
+			if vari.Verbose {
+				logUtils.Screen(i118Utils.I118Prt.Sprintf("server_return") + " HTML - " +
+					gohtml.FormatWithLineNo(string(bodyStr)))
 			}
+			if htmlIsOk {
+				return string(bodyStr), nil
+			}
+			zentaoErr.Html = true
+		} else {
+			if vari.Verbose {
+				logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("server_return")+jsonErr.Error(), color.FgRed)
+			}
+			zentaoErr.Message = jsonErr.Error()
 		}
-		ok = false
-		return
-	}
 
-	defer resp.Body.Close()
+		return "", zentaoErr
+	}
 
 	status := bodyJson.Status
 	if status == "" { // 非嵌套结构
-		msg = string(bodyStr)
-		return
+		return string(bodyStr), nil
+	} else if status != "success" {
+		return "", newZentaoError(method, url, http.StatusOK, getZentaoMessage(bodyStr))
 	} else { // 嵌套结构
-		msg = bodyJson.Data
-		ok = status == "success"
-		return
+		return bodyJson.Data, nil
 	}
 }
 
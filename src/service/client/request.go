@@ -0,0 +1,143 @@
+package client
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	constant "github.com/easysoft/zentaoatf/src/utils/const"
+	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
+	logUtils "github.com/easysoft/zentaoatf/src/utils/log"
+	"github.com/easysoft/zentaoatf/src/utils/vari"
+	"github.com/fatih/color"
+)
+
+// which failures of a request are retried
+type retryPolicy int
+
+const (
+	// idempotent requests, timeouts and temporary statuses are retried besides failures of connecting
+	retryIdempotent retryPolicy = iota
+	// requests not idempotent, only failures of connecting are retried, since zentao hasn't got them
+	retryNotSent
+	// requests which are safe to send again if zentao fails to handle them, such as the result of a run,
+	// 502 and 503 are retried too, which are returned by proxy when zentao is down or restarting
+	retryNotHandled
+)
+
+var timeoutOnce sync.Once
+
+// sendRequest sends the request created by newReq, failures are retried with exponential backoff by policy.
+func sendRequest(httpClient *http.Client, newReq func() (*http.Request, error), policy retryPolicy) ([]byte, error) {
+	timeoutOnce.Do(func() { // config is loaded after the clients are created
+		timeout := time.Duration(getRequestTimeout()) * time.Second
+		client.Timeout = timeout
+		postClient.Timeout = timeout
+	})
+
+	for retried := 0; ; retried++ {
+		body, retryable, zentaoErr := doRequest(httpClient, newReq, policy)
+		if zentaoErr == nil {
+			return body, nil
+		}
+		zentaoErr.Retried = retried
+
+		if !retryable || retried >= getRequestRetry() {
+			return body, zentaoErr
+		}
+
+		backoff := time.Duration(1<<uint(retried)) * time.Second
+		if vari.Cui == nil {
+			logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("zentao_request_retry", zentaoErr.Error(), backoff), color.FgYellow)
+		}
+		time.Sleep(backoff)
+	}
+}
+
+func doRequest(httpClient *http.Client, newReq func() (*http.Request, error), policy retryPolicy) (
+	body []byte, retryable bool, zentaoErr *ZentaoError) {
+
+	req, err := newReq()
+	if err != nil {
+		return nil, false, &ZentaoError{Message: err.Error()}
+	}
+
+	zentaoErr = newZentaoError(req.Method, req.URL.String(), 0, "")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		if vari.Verbose {
+			logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("server_return")+err.Error(), color.FgRed)
+		}
+
+		zentaoErr.Message = err.Error()
+		if urlErr, ok := err.(*url.Error); ok {
+			zentaoErr.Message = urlErr.Err.Error()
+			zentaoErr.Timeout = urlErr.Timeout()
+		}
+
+		// errors like unsupported protocol scheme won't be fixed by retrying
+		retryable = isDialError(err) || (zentaoErr.Timeout && policy == retryIdempotent)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err = ioutil.ReadAll(resp.Body)
+	if vari.Verbose {
+		logUtils.Screen(i118Utils.I118Prt.Sprintf("server_return") + resp.Status + " " + logUtils.ConvertUnicode(body))
+	}
+	if err != nil {
+		zentaoErr.Message = err.Error()
+		zentaoErr.Timeout = isTimeout(err)
+		return nil, zentaoErr.Timeout && policy == retryIdempotent, zentaoErr
+	}
+
+	if resp.StatusCode >= 400 {
+		zentaoErr.StatusCode = resp.StatusCode
+		zentaoErr.Message = getZentaoMessage(body)
+
+		switch policy {
+		case retryIdempotent:
+			retryable = zentaoErr.temporary()
+		case retryNotHandled:
+			retryable = resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable
+		}
+		return body, retryable, zentaoErr
+	}
+
+	return body, false, nil
+}
+
+// failed to connect, such as connection refused, the request is not sent
+func isDialError(err error) bool {
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+
+	opErr, ok := err.(*net.OpError)
+	return ok && opErr.Op == "dial"
+}
+
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
+
+func getRequestTimeout() int {
+	if vari.Config.RequestTimeout > 0 {
+		return vari.Config.RequestTimeout
+	}
+	return constant.RequestTimeout
+}
+
+func getRequestRetry() int {
+	if vari.Config.RequestRetry > 0 {
+		return vari.Config.RequestRetry
+	} else if vari.Config.RequestRetry < 0 {
+		return 0
+	}
+	return constant.RequestRetry
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
+	logUtils "github.com/easysoft/zentaoatf/src/utils/log"
+	"github.com/easysoft/zentaoatf/src/utils/vari"
+)
+
+// GetRest requests restful api v1 of zentao, which is authorized by the token in header instead of session
+func GetRest(url string) (string, error) {
+	return requestRest("GET", url, nil, retryIdempotent)
+}
+
+func PostRest(url string, params interface{}) (string, error) {
+	return requestRest("POST", url, params, retryNotSent)
+}
+
+// PostRestResult posts the result of a run, it's sent again if zentao fails to handle it
+func PostRestResult(url string, params interface{}) (string, error) {
+	return requestRest("POST", url, params, retryNotHandled)
+}
+
+func PutRest(url string, params interface{}) (string, error) {
+	return requestRest("PUT", url, params, retryIdempotent)
+}
+
+// data of restful api is not wrapped with status, the status code tells whether it's successful,
+// and the error is returned like {"error": "Unauthorized"}
+func requestRest(method string, url string, params interface{}, policy retryPolicy) (string, error) {
+	body := ""
+	if params != nil {
+		jsonStr, _ := json.Marshal(params)
+		body = string(jsonStr)
+	}
+
+	if vari.Verbose {
+		logUtils.Screen(i118Utils.I118Prt.Sprintf("server_address") + method + " " + url)
+		if body != "" {
+			logUtils.Screen(i118Utils.I118Prt.Sprintf("server_params") + body)
+		}
+	}
+
+	bodyStr, err := sendRequest(client, func() (*http.Request, error) {
+		req, reqErr := http.NewRequest(method, url, strings.NewReader(body))
+		if reqErr == nil {
+			req.Header.Set("Content-Type", "application/json")
+			if vari.ZentaoToken != "" {
+				req.Header.Set("Token", vari.ZentaoToken)
+			}
+		}
+		return req, reqErr
+	}, policy)
+
+	return string(bodyStr), err
+}
@@ -60,6 +60,10 @@ func GenerateTestCaseScript(cs model.TestCase, langType string, independentFile
 	info = append(info, fmt.Sprintf("title=%s", caseTitle))
 	info = append(info, fmt.Sprintf("cid=%s", caseId))
 	info = append(info, fmt.Sprintf("pid=%s", productId))
+	if content != "" { // keep fields like tags and timeout written by user
+		oldInfo, _ := zentaoUtils.ReadCaseInfo(content, langType, isOldFormat)
+		info = append(info, zentaoUtils.ReadCaseInfoExtraLines(oldInfo)...)
+	}
 
 	StepWidth := 20
 	stepDisplayMaxWidth := 0
@@ -0,0 +1,119 @@
+package testingService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/easysoft/zentaoatf/src/model"
+	constant "github.com/easysoft/zentaoatf/src/utils/const"
+	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
+	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
+	"github.com/easysoft/zentaoatf/src/utils/vari"
+	"os"
+	"strconv"
+	"time"
+)
+
+type HistoryCase struct {
+	Name     string
+	Status   string
+	Duration float32
+}
+
+// SaveToHistory copies result.json of a run to history dir and adds it to the index
+func SaveToHistory(report model.TestReport) {
+	dir := fileUtils.GetHistoryDir()
+	fileUtils.MkDirIfNeeded(dir)
+
+	runs := ListHistory()
+
+	id := 1
+	if len(runs) > 0 {
+		id = runs[len(runs)-1].Id + 1
+	}
+
+	run := model.HistoryRun{Id: id, Time: time.Now().Unix(), LogDir: vari.LogDir,
+		TestType: report.TestType, TestFrame: report.TestFrame,
+		Pass: report.Pass, Fail: report.Fail, Skip: report.Skip, Total: report.Total, Duration: report.Duration}
+	runs = append(runs, run)
+
+	content, _ := json.Marshal(report)
+	fileUtils.WriteFile(getHistoryResultFile(id), string(content))
+
+	// only keep the latest runs
+	for len(runs) > constant.HistoryMaxRuns {
+		os.Remove(getHistoryResultFile(runs[0].Id))
+		runs = runs[1:]
+	}
+
+	index, _ := json.Marshal(runs)
+	fileUtils.WriteFile(dir+constant.HistoryIndex, string(index))
+}
+
+func ListHistory() (runs []model.HistoryRun) {
+	pth := fileUtils.GetHistoryDir() + constant.HistoryIndex
+	if !fileUtils.FileExist(pth) {
+		return
+	}
+
+	json.Unmarshal(fileUtils.ReadFileBuf(pth), &runs)
+	return
+}
+
+// GetHistoryReport loads the report of a run, which may be a history id, a log dir or a result.json file
+func GetHistoryReport(run string) (report model.TestReport, err error) {
+	pth := run
+	if id, err1 := strconv.Atoi(run); err1 == nil {
+		pth = getHistoryResultFile(id)
+	} else if fileUtils.IsDir(run) {
+		pth = fileUtils.AddPathSepIfNeeded(run) + "result.json"
+	}
+
+	if !fileUtils.FileExist(pth) {
+		err = errors.New(i118Utils.I118Prt.Sprintf("history_run_not_found", run))
+		return
+	}
+
+	err = json.Unmarshal(fileUtils.ReadFileBuf(pth), &report)
+	return
+}
+
+// GetHistoryCases returns the cases of a report keyed by script path, or by suite and title for unit test
+func GetHistoryCases(report model.TestReport) (keys []string, cases map[string]HistoryCase) {
+	cases = map[string]HistoryCase{}
+
+	for _, cs := range report.FuncResult {
+		key := cs.Path
+		if _, ok := cases[key]; !ok {
+			keys = append(keys, key)
+		}
+		cases[key] = HistoryCase{Name: fmt.Sprintf("[%s] %d.%s", cs.Path, cs.Id, cs.Title),
+			Status: getHistoryStatus(cs.Status), Duration: cs.Duration}
+	}
+
+	for _, cs := range report.UnitResult {
+		key := cs.TestSuite + "." + cs.Title
+		if _, ok := cases[key]; !ok {
+			keys = append(keys, key)
+		}
+		cases[key] = HistoryCase{Name: key, Status: getHistoryStatus(cs.Status), Duration: cs.Duration}
+	}
+
+	return
+}
+
+func getHistoryResultFile(id int) string {
+	return fmt.Sprintf("%s%03d.json", fileUtils.GetHistoryDir(), id)
+}
+
+// timeout and error are counted as fail, and blocked as skip
+func getHistoryStatus(status string) string {
+	switch status {
+	case constant.PASS.String():
+		return constant.PASS.String()
+	case constant.FAIL.String(), constant.TIMEOUT.String(), constant.ERROR.String():
+		return constant.FAIL.String()
+	default:
+		return constant.SKIP.String()
+	}
+}
@@ -0,0 +1,245 @@
+package testingService
+
+import (
+	"errors"
+	"fmt"
+	"github.com/easysoft/zentaoatf/src/model"
+	constant "github.com/easysoft/zentaoatf/src/utils/const"
+	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
+	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type htmlReport struct {
+	Title       string
+	GeneratedAt string
+	Report      model.TestReport
+
+	PassPercent  float64
+	FailPercent  float64
+	ErrorPercent float64
+	SkipPercent  float64
+
+	Cases   []htmlCase
+	RawLogs []htmlRawLog
+}
+
+type htmlCase struct {
+	Name     string
+	Path     string
+	Status   string
+	Duration string
+	Steps    []model.StepLog
+	Failure  string
+	Output   string
+	LogId    string
+	Rows     []htmlCase
+}
+
+type htmlRawLog struct {
+	Id      string
+	Name    string
+	Content string
+}
+
+var rawLogStartRegx = regexp.MustCompile(`^===start (.+) at \d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}$`)
+
+// GenHtmlReport generates a single offline html file from result.json and log.txt in the log dir
+func GenHtmlReport(resultDir string) (file string, err error) {
+	report := GetZTFTestReportForSubmit(resultDir)
+	if report.TestType == "" {
+		return "", errors.New(i118Utils.I118Prt.Sprintf("no_result_in_dir", resultDir))
+	}
+
+	data := htmlReport{
+		Title:       i118Utils.I118Prt.Sprintf("html_report_title"),
+		GeneratedAt: time.Now().Format("2006-01-02 15:04:05"),
+		Report:      report,
+	}
+	if report.Total > 0 {
+		data.PassPercent = float64(report.Pass) * 100 / float64(report.Total)
+		data.FailPercent = float64(report.Fail) * 100 / float64(report.Total)
+		data.ErrorPercent = float64(report.Error) * 100 / float64(report.Total)
+		data.SkipPercent = float64(report.Skip) * 100 / float64(report.Total)
+	}
+
+	logContent := fileUtils.ReadFile(resultDir + "log.txt")
+	if report.TestType == "unit" {
+		for _, cs := range report.UnitResult {
+			data.Cases = append(data.Cases, convertUnitResultToHtml(cs))
+		}
+		data.RawLogs = append(data.RawLogs, htmlRawLog{Id: "log-0", Name: "log.txt", Content: logContent})
+	} else {
+		rawLogs := splitRawLogByScript(logContent)
+		for idx, cs := range report.FuncResult {
+			htmlCs := convertFuncResultToHtml(cs)
+			if content, ok := rawLogs[cs.Path]; ok {
+				htmlCs.LogId = "log-" + strconv.Itoa(idx)
+				data.RawLogs = append(data.RawLogs, htmlRawLog{Id: htmlCs.LogId, Name: cs.Path, Content: content})
+			}
+			data.Cases = append(data.Cases, htmlCs)
+		}
+	}
+
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"i18n": func(key string) string { return i118Utils.I118Prt.Sprintf(key) },
+		"boolStatus": func(pass bool) string {
+			if pass {
+				return constant.PASS.String()
+			}
+			return constant.FAIL.String()
+		},
+		"trimNumb": func(numb string) string { return strings.TrimRight(numb, ".") },
+	}).Parse(htmlReportTemplate)
+	if err != nil {
+		return
+	}
+
+	var buf strings.Builder
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return
+	}
+
+	file = resultDir + "result.html"
+	fileUtils.WriteFile(file, buf.String())
+
+	return
+}
+
+func convertFuncResultToHtml(cs model.FuncResult) htmlCase {
+	ret := htmlCase{Name: fmt.Sprintf("%d.%s", cs.Id, cs.Title), Path: cs.Path, Status: cs.Status,
+		Duration: fmt.Sprintf("%.2fs", cs.Duration), Steps: cs.Steps}
+
+	for _, sub := range cs.SubResults {
+		ret.Rows = append(ret.Rows, convertFuncResultToHtml(sub))
+	}
+
+	return ret
+}
+
+func convertUnitResultToHtml(cs model.UnitResult) htmlCase {
+	ret := htmlCase{Name: fmt.Sprintf("%d.%s", cs.Id, cs.Title), Path: cs.TestSuite, Status: cs.Status,
+		Duration: fmt.Sprintf("%.3fs", cs.Duration)}
+
+	if cs.Failure != nil {
+		ret.Failure = strings.TrimSpace(cs.Failure.Type + "\n" + cs.Failure.Desc)
+	} else if cs.Error != nil {
+		ret.Failure = strings.TrimSpace(cs.Error.Type + "\n" + cs.Error.Desc)
+	}
+	if ret.Failure != "" {
+		ret.Output = GetUnitOutputText(cs)
+	}
+
+	return ret
+}
+
+// lines between "===start <script> at" and "===end <script> at" in log.txt are the raw log of the script
+func splitRawLogByScript(content string) map[string]string {
+	ret := map[string]string{}
+
+	script := ""
+	lines := make([]string, 0)
+	for _, line := range strings.Split(content, "\n") {
+		if arr := rawLogStartRegx.FindStringSubmatch(line); arr != nil {
+			script = arr[1]
+			lines = make([]string, 0)
+		}
+		if script == "" {
+			continue
+		}
+
+		lines = append(lines, line)
+
+		if strings.HasPrefix(line, "===end "+script+" at ") {
+			ret[script] = strings.Join(lines, "\n")
+			script = ""
+		}
+	}
+
+	return ret
+}
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: Arial, "Microsoft YaHei", sans-serif; margin: 20px; color: #333; }
+table { border-collapse: collapse; width: 100%; margin: 8px 0; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f5f5f5; }
+pre { background: #f8f8f8; padding: 8px; white-space: pre-wrap; word-break: break-all; margin: 4px 0; }
+summary { cursor: pointer; padding: 4px 0; }
+.chart { display: flex; height: 20px; width: 100%; max-width: 600px; margin: 8px 0; background: #eee; }
+.pass { color: #2e7d32; } .fail, .timeout { color: #c62828; } .skip, .blocked { color: #f9a825; } .error { color: #8e24aa; }
+.bar-pass { background: #43a047; } .bar-fail { background: #e53935; } .bar-skip { background: #fdd835; } .bar-error { background: #8e24aa; }
+.meta { color: #888; font-size: 12px; }
+</style>
+</head>
+<body>
+<h2>{{.Title}}</h2>
+<p class="meta">{{.Report.TestType}} / {{.Report.TestFrame}} / {{.Report.Env}} &nbsp; {{i18n "html_report_generated"}} {{.GeneratedAt}}</p>
+
+<table style="width: auto">
+<tr><th>{{i18n "total"}}</th><th class="pass">{{i18n "pass"}}</th><th class="fail">{{i18n "fail"}}</th>{{if .Report.Error}}<th class="error">{{i18n "error"}}</th>{{end}}<th class="skip">{{i18n "skip"}}</th><th>{{i18n "duration"}}</th></tr>
+<tr><td>{{.Report.Total}}</td><td>{{.Report.Pass}}</td><td>{{.Report.Fail}}</td>{{if .Report.Error}}<td>{{.Report.Error}}</td>{{end}}<td>{{.Report.Skip}}</td><td>{{.Report.Duration}}s</td></tr>
+</table>
+<div class="chart">
+<div class="bar-pass" style="width: {{printf "%.1f" .PassPercent}}%" title="{{i18n "pass"}} {{printf "%.1f" .PassPercent}}%"></div>
+<div class="bar-fail" style="width: {{printf "%.1f" .FailPercent}}%" title="{{i18n "fail"}} {{printf "%.1f" .FailPercent}}%"></div>
+{{if .Report.Error}}<div class="bar-error" style="width: {{printf "%.1f" .ErrorPercent}}%" title="{{i18n "error"}} {{printf "%.1f" .ErrorPercent}}%"></div>{{end}}
+<div class="bar-skip" style="width: {{printf "%.1f" .SkipPercent}}%" title="{{i18n "skip"}} {{printf "%.1f" .SkipPercent}}%"></div>
+</div>
+
+{{range .Cases}}
+<details{{if or (eq .Status "fail") (eq .Status "timeout") (eq .Status "error")}} open{{end}}>
+<summary><span class="{{.Status}}">[{{i18n .Status}}]</span> {{.Name}} <span class="meta">{{.Path}} ({{.Duration}})</span>
+{{if .LogId}} <a href="#{{.LogId}}">{{i18n "raw_log"}}</a>{{end}}</summary>
+{{template "steps" .}}
+{{if .Rows}}
+<p>{{i18n "data_rows"}}</p>
+{{range .Rows}}
+<details{{if or (eq .Status "fail") (eq .Status "timeout")}} open{{end}} style="margin-left: 20px">
+<summary><span class="{{.Status}}">[{{i18n .Status}}]</span> {{.Name}} <span class="meta">({{.Duration}})</span></summary>
+{{template "steps" .}}
+</details>
+{{end}}
+{{end}}
+</details>
+{{end}}
+
+{{if .RawLogs}}
+<h3>{{i18n "raw_log"}}</h3>
+{{range .RawLogs}}
+<details id="{{.Id}}">
+<summary>{{.Name}}</summary>
+<pre>{{.Content}}</pre>
+</details>
+{{end}}
+{{end}}
+<script>
+document.querySelectorAll('a[href^="#log-"]').forEach(function (a) {
+  a.addEventListener('click', function () { document.querySelector(a.getAttribute('href')).open = true; });
+});
+</script>
+</body>
+</html>
+
+{{define "steps"}}
+{{if .Failure}}<pre class="fail">{{.Failure}}</pre>{{end}}
+{{if .Output}}<pre>{{.Output}}</pre>{{end}}
+{{if .Steps}}
+<table>
+<tr><th>{{i18n "step"}}</th><th>{{i18n "result"}}</th><th>{{i18n "expect_result"}}</th><th>{{i18n "actual_result"}}</th></tr>
+{{range .Steps}}{{$step := .}}{{range .CheckPoints}}
+<tr><td>{{trimNumb $step.Id}}</td><td class="{{boolStatus .Status}}">{{boolStatus .Status | i18n}}</td><td><pre>{{.Expect}}</pre></td><td><pre>{{.Actual}}</pre></td></tr>
+{{end}}{{end}}
+</table>
+{{end}}
+{{end}}
+`
@@ -0,0 +1,89 @@
+package testingService
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/easysoft/zentaoatf/src/model"
+	constant "github.com/easysoft/zentaoatf/src/utils/const"
+	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GenJUnitReport writes functional test results to a junit xml file, which can be shown by CI tools
+func GenJUnitReport(report model.TestReport, file string) {
+	suite := ConvertFuncResultToJUnit(report)
+
+	bytes, _ := xml.MarshalIndent(suite, "", "  ")
+
+	fileUtils.MkDirIfNeeded(filepath.Dir(file))
+	fileUtils.WriteFile(file, xml.Header+string(bytes))
+}
+
+func ConvertFuncResultToJUnit(report model.TestReport) model.UnitTestSuite {
+	suite := model.UnitTestSuite{Name: constant.AppName}
+
+	for _, cs := range report.FuncResult {
+		// each row of a data-driven case is a testcase
+		results := cs.SubResults
+		if len(results) == 0 {
+			results = []model.FuncResult{cs}
+		}
+
+		for _, result := range results {
+			suite.TestCases = append(suite.TestCases, convertFuncResultToJUnitCase(result))
+		}
+	}
+
+	// time of suite is the sum of cases in float, the duration of report is in integer seconds
+	for _, cs := range suite.TestCases {
+		suite.Time += cs.Duration
+		suite.Tests++
+		if cs.Failure != nil {
+			suite.Failures++
+		} else if cs.Skipped != nil {
+			suite.Skipped++
+		}
+	}
+
+	return suite
+}
+
+func convertFuncResultToJUnitCase(cs model.FuncResult) model.UnitResult {
+	testCase := model.UnitResult{Title: fmt.Sprintf("%d.%s", cs.Id, cs.Title), TestSuite: cs.Path,
+		Duration: cs.Duration, Status: cs.Status}
+	if cs.Id > 0 {
+		testCase.Properties = &model.Properties{
+			Property: []model.Property{{Name: UnitCaseIdProperty, Value: strconv.Itoa(cs.Id)}}}
+	}
+
+	switch cs.Status {
+	case constant.FAIL.String(), constant.TIMEOUT.String():
+		steps := make([]string, 0)
+		for _, step := range cs.Steps {
+			if !step.Status {
+				steps = append(steps, GetStepText(step))
+			}
+		}
+
+		// failure desc is written as inner xml, keep it in cdata
+		desc := strings.Replace(stripInvalidXmlChars(strings.Join(steps, "\n")), "]]>", "]]]]><![CDATA[>", -1)
+		testCase.Failure = &model.Failure{Type: cs.Status, Desc: "<![CDATA[" + desc + "]]>"}
+	case constant.SKIP.String(), constant.BLOCKED.String():
+		testCase.Skipped = &model.Skipped{Message: cs.Status}
+	}
+
+	return testCase
+}
+
+// control characters like \x1b of colored output are not allowed in xml, even in cdata
+func stripInvalidXmlChars(str string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' ||
+			r >= 0x20 && r <= 0xD7FF || r >= 0xE000 && r <= 0xFFFD || r >= 0x10000 && r <= 0x10FFFF {
+			return r
+		}
+		return -1
+	}, str)
+}
@@ -0,0 +1,72 @@
+package testingService
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/easysoft/zentaoatf/src/model"
+	constant "github.com/easysoft/zentaoatf/src/utils/const"
+	"strings"
+)
+
+// GenTapReport returns functional test results as tap version 13, so that it can be consumed by prove or other tap harness.
+// each row of a data-driven case is a test, failed checkpoints are put in the yaml diagnostic.
+func GenTapReport(report model.TestReport) string {
+	results := make([]model.FuncResult, 0)
+	for _, cs := range report.FuncResult {
+		if len(cs.SubResults) > 0 {
+			results = append(results, cs.SubResults...)
+		} else {
+			results = append(results, cs)
+		}
+	}
+
+	lines := []string{"TAP version 13", fmt.Sprintf("1..%d", len(results))}
+	for idx, cs := range results {
+		desc := strings.Replace(fmt.Sprintf("%d.%s", cs.Id, cs.Title), "#", `\#`, -1)
+
+		switch cs.Status {
+		case constant.PASS.String():
+			lines = append(lines, fmt.Sprintf("ok %d - %s", idx+1, desc))
+		case constant.SKIP.String(), constant.BLOCKED.String():
+			lines = append(lines, fmt.Sprintf("ok %d - %s # SKIP %s", idx+1, desc, cs.Status))
+		default:
+			lines = append(lines, fmt.Sprintf("not ok %d - %s", idx+1, desc))
+			lines = append(lines, getTapDiagnostic(cs)...)
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func getTapDiagnostic(cs model.FuncResult) []string {
+	lines := []string{"  ---",
+		"  message: " + tapQuote(cs.Status),
+		"  severity: fail",
+		"  file: " + tapQuote(cs.Path),
+		fmt.Sprintf("  duration_ms: %d", int(cs.Duration*1000))}
+
+	checkpoints := make([]string, 0)
+	for _, step := range cs.Steps {
+		for _, cp := range step.CheckPoints {
+			if cp.Status {
+				continue
+			}
+
+			checkpoints = append(checkpoints, "    - step: "+tapQuote(strings.TrimRight(step.Id, ".")),
+				"      expect: "+tapQuote(cp.Expect),
+				"      actual: "+tapQuote(cp.Actual))
+		}
+	}
+	if len(checkpoints) > 0 {
+		lines = append(lines, "  checkpoints:")
+		lines = append(lines, checkpoints...)
+	}
+
+	return append(lines, "  ...")
+}
+
+// json string is a valid double-quoted scalar of yaml
+func tapQuote(str string) string {
+	bytes, _ := json.Marshal(str)
+	return string(bytes)
+}
@@ -0,0 +1,97 @@
+package testingService
+
+import (
+	"github.com/easysoft/zentaoatf/src/model"
+	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
+	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
+	logUtils "github.com/easysoft/zentaoatf/src/utils/log"
+	"github.com/easysoft/zentaoatf/src/utils/vari"
+	"github.com/fatih/color"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const UnitCaseIdProperty = "zentao.caseId"
+
+// _case123 at the end of name, like test_login_case123, TestLogin_Case123 or test_login_case123[param],
+// names like test_case_1 or TestEdgeCase2 are not treated as zentao case 1 and 2.
+var unitCaseIdRegx = regexp.MustCompile(`[_-][Cc]ase(\d+)(?:\[.*\]|\(.*\))?$`)
+
+// getUnitCaseId gets zentao case id of a unit test, from the mapping file, the zentao.caseId property or the test name in turn,
+// the rule of name can be replaced by UnitCaseIdRegx in config, whose first group is the id, like zentao(\d+).
+func getUnitCaseId(cs model.UnitResult, caseMap map[string]int) int {
+	if id, ok := caseMap[cs.TestSuite+"."+cs.Title]; ok {
+		return id
+	}
+	if id, ok := caseMap[cs.Title]; ok {
+		return id
+	}
+
+	if cs.Properties != nil {
+		for _, prop := range cs.Properties.Property {
+			if prop.Name == UnitCaseIdProperty {
+				id, _ := strconv.Atoi(strings.TrimSpace(prop.Value))
+				return id
+			}
+		}
+	}
+
+	arr := getUnitCaseIdRegx().FindStringSubmatch(cs.Title)
+	if len(arr) < 2 {
+		return 0
+	}
+
+	id, _ := strconv.Atoi(arr[1])
+	return id
+}
+
+func getUnitCaseIdRegx() *regexp.Regexp {
+	if vari.Config.UnitCaseIdRegx == "" {
+		return unitCaseIdRegx
+	}
+
+	regx, err := regexp.Compile(vari.Config.UnitCaseIdRegx)
+	if err != nil || regx.NumSubexp() < 1 {
+		logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("invalid_unit_case_id_regx", vari.Config.UnitCaseIdRegx), color.FgYellow)
+		vari.Config.UnitCaseIdRegx = "" // warn only once
+		return unitCaseIdRegx
+	}
+
+	return regx
+}
+
+// the mapping file has lines like "com.demo.LoginTest.testLogin = 123" or "testLogin = 123", # for comments
+func readUnitCaseMap(file string) (caseMap map[string]int) {
+	caseMap = map[string]int{}
+	if file == "" {
+		return
+	}
+
+	if vari.ServerProjectDir != "" {
+		file = vari.ServerProjectDir + file
+	}
+	if !fileUtils.FileExist(file) {
+		logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("case_map_not_found", file), color.FgYellow)
+		return
+	}
+
+	for _, line := range strings.Split(fileUtils.ReadFile(file), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		arr := strings.SplitN(line, "=", 2)
+		if len(arr) < 2 {
+			continue
+		}
+
+		id, err := strconv.Atoi(strings.TrimSpace(arr[1]))
+		if err == nil {
+			caseMap[strings.TrimSpace(arr[0])] = id
+		}
+	}
+
+	return
+}
@@ -0,0 +1,126 @@
+package testingService
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"github.com/easysoft/zentaoatf/src/model"
+	"io"
+	"strings"
+)
+
+const trxNamespace = "http://microsoft.com/schemas/VisualStudio/TeamTest/2010"
+
+// elements and attributes of a result file used to detect its format,
+// formats sharing the <testsuites> root are told apart by their generators' particular attributes.
+type xmlResultInfo struct {
+	Root      string
+	RootName  string
+	RootSpace string
+
+	CaseAttrs    map[string]bool
+	SuiteNames   map[string]bool
+	SuiteHasFile bool
+	IsQTest      bool
+}
+
+func getXmlResultInfo(content string) (info xmlResultInfo, ok bool) {
+	info.CaseAttrs = map[string]bool{}
+	info.SuiteNames = map[string]bool{}
+
+	content = strings.Replace(strings.TrimSpace(content), "ISO-8859-1", "UTF-8", 1)
+	if !strings.HasPrefix(content, "<") {
+		return
+	}
+
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return
+		}
+
+		elem, isElem := token.(xml.StartElement)
+		if !isElem {
+			continue
+		}
+
+		attrs := map[string]string{}
+		for _, attr := range elem.Attr {
+			attrs[attr.Name.Local] = attr.Value
+		}
+
+		if info.Root == "" {
+			info.Root = elem.Name.Local
+			info.RootName = attrs["name"]
+			info.RootSpace = elem.Name.Space
+		}
+
+		switch elem.Name.Local {
+		case "testsuite":
+			info.SuiteNames[attrs["name"]] = true
+			if _, found := attrs["file"]; found {
+				info.SuiteHasFile = true
+			}
+		case "testcase":
+			for name := range attrs {
+				info.CaseAttrs[name] = true
+			}
+		case "property":
+			if attrs["name"] == "QTestVersion" {
+				info.IsQTest = true
+			}
+		}
+	}
+
+	ok = info.Root != ""
+	return
+}
+
+// detectXmlResult returns a detect function of parser, which checks the info of xml result file
+func detectXmlResult(check func(info xmlResultInfo) bool) func(content string) bool {
+	return func(content string) bool {
+		info, ok := getXmlResultInfo(content)
+		return ok && check(info)
+	}
+}
+
+func isQTestResult(info xmlResultInfo) bool {
+	return info.Root == "testsuite" && (info.IsQTest || info.CaseAttrs["result"])
+}
+
+func isGTestResult(info xmlResultInfo) bool {
+	return info.Root == "testsuites" && info.CaseAttrs["status"]
+}
+
+func isCypressResult(info xmlResultInfo) bool {
+	return info.Root == "testsuites" && !isGTestResult(info) &&
+		(info.RootName == "Mocha Tests" || info.SuiteNames["Root Suite"] || info.SuiteHasFile)
+}
+
+func isPyTestResult(info xmlResultInfo) bool {
+	return info.Root == "testsuites" && !isGTestResult(info) && !isCypressResult(info) && info.SuiteNames["pytest"]
+}
+
+// jest converter works for general junit xml with <testsuites> root
+func isJestResult(info xmlResultInfo) bool {
+	return info.Root == "testsuites" && !isGTestResult(info) && !isCypressResult(info) && !isPyTestResult(info)
+}
+
+func isGoTestResult(content string) bool {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "{") {
+		return false
+	}
+
+	event := model.GoTestEvent{}
+	line := strings.SplitN(content, "\n", 2)[0]
+	return json.Unmarshal([]byte(line), &event) == nil && event.Action != ""
+}
+
+func isTapResult(content string) bool {
+	content = strings.TrimSpace(content)
+	return tapTestRegx.MatchString(content) || tapPlanRegx.MatchString(content) || strings.HasPrefix(content, "TAP version")
+}
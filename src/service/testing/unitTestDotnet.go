@@ -0,0 +1,221 @@
+package testingService
+
+import (
+	"github.com/easysoft/zentaoatf/src/model"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConvertNUnitResult converts nunit3 xml, test cases may be nested in assembly, namespace and fixture suites
+func ConvertNUnitResult(result model.NUnitTestRun) model.UnitTestSuite {
+	testSuite := model.UnitTestSuite{}
+	testSuite.Time = result.Duration
+
+	for _, suite := range result.TestSuites {
+		retrieveNUnitTests(suite, &testSuite)
+	}
+
+	return testSuite
+}
+
+func retrieveNUnitTests(suite model.NUnitTestSuite, testSuite *model.UnitTestSuite) {
+	for _, child := range suite.TestSuites {
+		retrieveNUnitTests(child, testSuite)
+	}
+
+	for _, cs := range suite.TestCases {
+		caseResult := model.UnitResult{}
+		caseResult.Title = cs.Name
+		caseResult.TestSuite = cs.ClassName
+		if caseResult.TestSuite == "" {
+			caseResult.TestSuite = suite.FullName
+		}
+		caseResult.Duration = cs.Duration
+		caseResult.StartTime = parseDotnetTime(cs.StartTime)
+		caseResult.EndTime = parseDotnetTime(cs.EndTime)
+		caseResult.SystemOut = cs.Output
+
+		props := cs.Properties
+		for _, item := range cs.Attachments {
+			props.Property = append(props.Property, model.Property{Name: "attachment", Value: item.FilePath})
+		}
+		if len(props.Property) > 0 {
+			caseResult.Properties = &props
+		}
+
+		switch cs.Result {
+		case "Failed":
+			fail := model.Failure{Type: cs.Label}
+			if cs.Failure != nil {
+				fail.Message = strings.TrimSpace(cs.Failure.Message)
+				fail.Desc = joinDotnetFailure(cs.Failure.Message, cs.Failure.StackTrace)
+			}
+
+			// label is Error for an unexpected exception, Invalid or Cancelled if the test can't run to the end
+			if cs.Label == "Error" || cs.Label == "Invalid" || cs.Label == "Cancelled" {
+				caseResult.Error = &fail
+			} else {
+				caseResult.Failure = &fail
+			}
+		case "Skipped", "Inconclusive":
+			skipped := model.Skipped{}
+			if cs.Reason != nil {
+				skipped.Message = strings.TrimSpace(cs.Reason.Message)
+			}
+			caseResult.Skipped = &skipped
+		}
+
+		testSuite.TestCases = append(testSuite.TestCases, caseResult)
+	}
+}
+
+// ConvertXUnitResult converts xunit v2 xml, traits of a test are kept as properties,
+// errors of an assembly such as failed fixture cleanup are reported as error cases.
+func ConvertXUnitResult(result model.XUnitAssemblies) model.UnitTestSuite {
+	testSuite := model.UnitTestSuite{}
+
+	for _, assembly := range result.Assemblies {
+		testSuite.Time += assembly.Time
+
+		for _, collection := range assembly.Collections {
+			for _, cs := range collection.Tests {
+				caseResult := model.UnitResult{}
+				caseResult.TestSuite = cs.Type
+				caseResult.Title = strings.TrimPrefix(cs.Name, cs.Type+".")
+				caseResult.Duration = cs.Time
+				caseResult.SystemOut = cs.Output
+
+				if len(cs.Traits) > 0 {
+					caseResult.Properties = &model.Properties{Property: cs.Traits}
+				}
+
+				switch cs.Result {
+				case "Fail":
+					fail := model.Failure{}
+					if cs.Failure != nil {
+						fail.Type = cs.Failure.ExceptionType
+						fail.Message = strings.TrimSpace(cs.Failure.Message)
+						fail.Desc = joinDotnetFailure(cs.Failure.Message, cs.Failure.StackTrace)
+					}
+					caseResult.Failure = &fail
+				case "Skip":
+					caseResult.Skipped = &model.Skipped{Message: strings.TrimSpace(cs.Reason)}
+				}
+
+				testSuite.TestCases = append(testSuite.TestCases, caseResult)
+			}
+		}
+
+		for _, item := range assembly.Errors {
+			caseResult := model.UnitResult{}
+			caseResult.TestSuite = filepath.Base(strings.Replace(assembly.Name, "\\", "/", -1))
+			caseResult.Title = item.Type
+			if item.Name != "" {
+				caseResult.Title += " " + item.Name
+			}
+
+			fail := model.Failure{Type: item.Type}
+			if item.Failure != nil {
+				fail.Type = item.Failure.ExceptionType
+				fail.Message = strings.TrimSpace(item.Failure.Message)
+				fail.Desc = joinDotnetFailure(item.Failure.Message, item.Failure.StackTrace)
+			}
+			caseResult.Error = &fail
+
+			testSuite.TestCases = append(testSuite.TestCases, caseResult)
+		}
+	}
+
+	return testSuite
+}
+
+// ConvertTrxResult converts visual studio trx, the class of a test is found in its definition
+func ConvertTrxResult(result model.TrxTestRun) model.UnitTestSuite {
+	testSuite := model.UnitTestSuite{}
+
+	classMap := map[string]string{}
+	propMap := map[string][]model.Property{}
+	for _, def := range result.Definitions {
+		classMap[def.Id] = def.Method.ClassName
+		for _, prop := range def.Properties {
+			propMap[def.Id] = append(propMap[def.Id], model.Property{Name: prop.Key, Value: prop.Value})
+		}
+	}
+
+	for _, cs := range result.Results {
+		caseResult := model.UnitResult{}
+		caseResult.TestSuite = classMap[cs.TestId]
+		caseResult.Title = strings.TrimPrefix(cs.TestName, caseResult.TestSuite+".")
+		caseResult.Duration = parseTrxDuration(cs.Duration)
+		caseResult.StartTime = parseDotnetTime(cs.StartTime)
+		caseResult.EndTime = parseDotnetTime(cs.EndTime)
+		caseResult.SystemOut = cs.StdOut
+		caseResult.SystemErr = cs.StdErr
+
+		testSuite.Time += caseResult.Duration
+
+		if props, ok := propMap[cs.TestId]; ok {
+			caseResult.Properties = &model.Properties{Property: props}
+		}
+
+		fail := model.Failure{Type: strings.ToLower(cs.Outcome)}
+		if cs.ErrorInfo != nil {
+			fail.Message = strings.TrimSpace(cs.ErrorInfo.Message)
+			fail.Desc = joinDotnetFailure(cs.ErrorInfo.Message, cs.ErrorInfo.StackTrace)
+		}
+
+		switch cs.Outcome {
+		case "Failed", "Timeout":
+			caseResult.Failure = &fail
+		case "Error", "Aborted":
+			caseResult.Error = &fail
+		case "NotExecuted", "Inconclusive", "NotRunnable", "Disconnected":
+			caseResult.Skipped = &model.Skipped{Message: fail.Message}
+		}
+
+		testSuite.TestCases = append(testSuite.TestCases, caseResult)
+	}
+
+	return testSuite
+}
+
+func joinDotnetFailure(message string, stackTrace string) string {
+	message = strings.TrimSpace(message)
+	stackTrace = strings.TrimRight(strings.Trim(stackTrace, "\r\n"), " ")
+
+	if stackTrace == "" {
+		return message
+	}
+	return message + "\n" + stackTrace
+}
+
+// nunit writes time like 2021-03-01 10:07:24Z, trx uses RFC3339 with 7 fractional digits
+func parseDotnetTime(str string) int64 {
+	if str == "" {
+		return 0
+	}
+
+	for _, templ := range []string{time.RFC3339Nano, "2006-01-02 15:04:05Z07:00", "2006-01-02 15:04:05.999999999Z07:00"} {
+		if tm, err := time.Parse(templ, str); err == nil {
+			return tm.Unix()
+		}
+	}
+
+	return 0
+}
+
+// duration in trx is like 00:00:01.2345678
+func parseTrxDuration(str string) float32 {
+	arr := strings.Split(str, ":")
+	if len(arr) != 3 {
+		return 0
+	}
+
+	hours, _ := strconv.Atoi(arr[0])
+	minutes, _ := strconv.Atoi(arr[1])
+	seconds, _ := strconv.ParseFloat(arr[2], 64)
+
+	return float32(float64(hours*3600+minutes*60) + seconds)
+}
@@ -0,0 +1,168 @@
+package testingService
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/easysoft/zentaoatf/src/model"
+)
+
+const dotnetFixtureDir = "../../../demo/unittest/dotnet"
+
+func TestConvertNUnitResult(t *testing.T) {
+	var result model.NUnitTestRun
+	readDotnetFixture(t, "nunit3.xml", &result)
+
+	suite := ConvertNUnitResult(result)
+	checkDotnetCounts(t, suite, 4, 1, 1, 1)
+	checkFloat(t, "suite time", suite.Time, 0.842)
+
+	add := findDotnetCase(t, suite, "Add")
+	if add.TestSuite != "Demo.Tests.CalculatorTests" || add.Properties == nil ||
+		add.Properties.Property[0].Value != "1" {
+		t.Errorf("Add = %+v", add)
+	}
+	if add.StartTime != 1614593244 {
+		t.Errorf("start time of Add = %d", add.StartTime)
+	}
+
+	divide := findDotnetCase(t, suite, "Divide")
+	checkFloat(t, "time of Divide", divide.Duration, 0.035)
+	if divide.Failure == nil || divide.Failure.Message != "Expected: 2\n  But was:  3" ||
+		!strings.HasSuffix(divide.Failure.Desc, "\n   at Demo.Tests.CalculatorTests.Divide() in /src/Demo.Tests/CalculatorTests.cs:line 25") {
+		t.Errorf("failure of Divide = %+v", divide.Failure)
+	}
+	if divide.SystemOut != "divide 6 by 2\n" || divide.Properties == nil ||
+		divide.Properties.Property[0].Value != "divide.log" {
+		t.Errorf("output or attachment of Divide = %q, %+v", divide.SystemOut, divide.Properties)
+	}
+
+	byZero := findDotnetCase(t, suite, "DivideByZero")
+	if byZero.Error == nil || byZero.Failure != nil || strings.Count(byZero.Error.Desc, "\n   at ") != 2 {
+		t.Errorf("error of DivideByZero = %+v", byZero.Error)
+	}
+
+	power := findDotnetCase(t, suite, "Power")
+	if power.Skipped == nil || power.Skipped.Message != "not implemented yet" {
+		t.Errorf("skipped of Power = %+v", power.Skipped)
+	}
+}
+
+func TestConvertXUnitResult(t *testing.T) {
+	var result model.XUnitAssemblies
+	readDotnetFixture(t, "xunit.xml", &result)
+
+	// the failed class cleanup is an error case besides 4 tests
+	suite := ConvertXUnitResult(result)
+	checkDotnetCounts(t, suite, 5, 1, 1, 1)
+	checkFloat(t, "suite time", suite.Time, 0.231)
+
+	login := findDotnetCase(t, suite, `Login(user: "admin")`)
+	if login.TestSuite != "Demo.Tests.LoginTests" || login.Properties == nil ||
+		login.Properties.Property[0].Name != UnitCaseIdProperty || login.Properties.Property[0].Value != "3" {
+		t.Errorf("Login = %+v", login)
+	}
+
+	logout := findDotnetCase(t, suite, "Logout")
+	checkFloat(t, "time of Logout", logout.Duration, 0.0561)
+	if logout.Failure == nil || logout.Failure.Type != "Xunit.Sdk.TrueException" ||
+		!strings.HasSuffix(logout.Failure.Desc, "Actual:   False\n   at Demo.Tests.LoginTests.Logout() in /src/Demo.Tests/LoginTests.cs:line 38") {
+		t.Errorf("failure of Logout = %+v", logout.Failure)
+	}
+	if logout.SystemOut != "logout from session 42\n" {
+		t.Errorf("output of Logout = %q", logout.SystemOut)
+	}
+
+	reset := findDotnetCase(t, suite, "ResetPassword")
+	if reset.Skipped == nil || reset.Skipped.Message != "mail server is not ready" {
+		t.Errorf("skipped of ResetPassword = %+v", reset.Skipped)
+	}
+
+	cleanup := findDotnetCase(t, suite, "class-cleanup Demo.Tests.LoginTests")
+	if cleanup.TestSuite != "Demo.Tests.dll" || cleanup.Error == nil || cleanup.Error.Type != "System.IO.IOException" ||
+		!strings.Contains(cleanup.Error.Desc, "DatabaseFixture.Dispose()") {
+		t.Errorf("class cleanup = %+v", cleanup)
+	}
+}
+
+func TestConvertTrxResult(t *testing.T) {
+	var result model.TrxTestRun
+	readDotnetFixture(t, "mstest.trx", &result)
+
+	suite := ConvertTrxResult(result)
+	checkDotnetCounts(t, suite, 3, 1, 0, 1)
+	checkFloat(t, "suite time", suite.Time, 0.0312+1.2504)
+
+	search := findDotnetCase(t, suite, "Search")
+	if search.TestSuite != "Demo.Tests.CartTests" || search.Properties == nil ||
+		search.Properties.Property[0].Value != "5" {
+		t.Errorf("Search = %+v", search)
+	}
+
+	addToCart := findDotnetCase(t, suite, "AddToCart")
+	checkFloat(t, "time of AddToCart", addToCart.Duration, 1.2504)
+	if addToCart.StartTime != 1614564444 || addToCart.EndTime != 1614564445 {
+		t.Errorf("time of AddToCart = %d - %d", addToCart.StartTime, addToCart.EndTime)
+	}
+	if addToCart.Failure == nil || addToCart.Failure.Message != "Assert.AreEqual failed. Expected:<1>. Actual:<0>." ||
+		!strings.HasSuffix(addToCart.Failure.Desc, "\n   at Demo.Tests.CartTests.AddToCart() in /src/Demo.Tests/CartTests.cs:line 27") {
+		t.Errorf("failure of AddToCart = %+v", addToCart.Failure)
+	}
+	if addToCart.SystemOut != "open product 1001" {
+		t.Errorf("output of AddToCart = %q", addToCart.SystemOut)
+	}
+
+	checkout := findDotnetCase(t, suite, "Checkout")
+	if checkout.Skipped == nil || checkout.Skipped.Message != "payment gateway is not configured" {
+		t.Errorf("skipped of Checkout = %+v", checkout.Skipped)
+	}
+}
+
+func readDotnetFixture(t *testing.T, name string, result interface{}) {
+	content, err := ioutil.ReadFile(filepath.Join(dotnetFixtureDir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = xml.Unmarshal(content, result); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkDotnetCounts(t *testing.T, suite model.UnitTestSuite, total int, failed int, errors int, skipped int) {
+	failures, errs, skips := 0, 0, 0
+	for _, cs := range suite.TestCases {
+		if cs.Failure != nil {
+			failures++
+		} else if cs.Error != nil {
+			errs++
+		} else if cs.Skipped != nil {
+			skips++
+		}
+	}
+
+	if len(suite.TestCases) != total || failures != failed || errs != errors || skips != skipped {
+		t.Errorf("counts = %d total, %d failed, %d errors, %d skipped, want %d, %d, %d, %d",
+			len(suite.TestCases), failures, errs, skips, total, failed, errors, skipped)
+	}
+}
+
+func findDotnetCase(t *testing.T, suite model.UnitTestSuite, title string) model.UnitResult {
+	for _, cs := range suite.TestCases {
+		if cs.Title == title {
+			return cs
+		}
+	}
+
+	t.Fatalf("case %s not found", title)
+	return model.UnitResult{}
+}
+
+func checkFloat(t *testing.T, name string, actual float32, expect float64) {
+	if math.Abs(float64(actual)-expect) > 0.0001 {
+		t.Errorf("%s = %v, want %v", name, actual, expect)
+	}
+}
@@ -0,0 +1,87 @@
+package testingService
+
+import (
+	"encoding/base64"
+	"github.com/easysoft/zentaoatf/src/model"
+	constant "github.com/easysoft/zentaoatf/src/utils/const"
+	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
+	logUtils "github.com/easysoft/zentaoatf/src/utils/log"
+	"github.com/easysoft/zentaoatf/src/utils/vari"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// [[ATTACHMENT|path]] in system-out or system-err, used by jenkins junit attachments plugin and mocha-junit-reporter
+var unitAttachmentRegx = regexp.MustCompile(`\[\[ATTACHMENT\|([^\]]+)\]\]`)
+
+// getUnitAttachments gets the files referred in the output or by attachment property of a unit test
+func getUnitAttachments(cs model.UnitResult) (attachments []model.Attachment) {
+	paths := make([]string, 0)
+	for _, arr := range unitAttachmentRegx.FindAllStringSubmatch(cs.SystemOut+"\n"+cs.SystemErr, -1) {
+		paths = append(paths, strings.TrimSpace(arr[1]))
+	}
+	if cs.Properties != nil {
+		for _, prop := range cs.Properties.Property {
+			if prop.Name == "attachment" {
+				paths = append(paths, strings.TrimSpace(prop.Value))
+			}
+		}
+	}
+
+	for _, pth := range paths {
+		if !filepath.IsAbs(pth) && vari.ServerProjectDir != "" {
+			pth = vari.ServerProjectDir + pth
+		}
+
+		fi, err := os.Stat(pth)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+
+		attachments = append(attachments, model.Attachment{Name: fi.Name(), Path: pth, Size: fi.Size()})
+	}
+
+	return
+}
+
+// LoadAttachments returns a copy of attachments with content of small files, which will be uploaded to zentao
+func LoadAttachments(attachments []model.Attachment) (ret []model.Attachment) {
+	for _, item := range attachments {
+		if item.Size <= constant.MaxAttachmentSize && fileUtils.FileExist(item.Path) {
+			item.Content = base64.StdEncoding.EncodeToString(fileUtils.ReadFileBuf(item.Path))
+		}
+		ret = append(ret, item)
+	}
+
+	return
+}
+
+// GetUnitOutputText returns the output and attachments of a unit test, which are shown for failed tests
+func GetUnitOutputText(cs model.UnitResult) string {
+	lines := make([]string, 0)
+
+	for _, step := range cs.Steps { // the chain of robot keywords which failed
+		if !step.Status {
+			lines = append(lines, "[keyword] "+step.Id+" "+step.Name)
+		}
+	}
+	if out := strings.TrimSpace(cs.SystemOut); out != "" {
+		lines = append(lines, "[system-out]", out)
+	}
+	if out := strings.TrimSpace(cs.SystemErr); out != "" {
+		lines = append(lines, "[system-err]", out)
+	}
+	for _, item := range cs.Attachments {
+		lines = append(lines, "[attachment] "+item.Path)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func printUnitOutput(cs model.UnitResult) {
+	if text := GetUnitOutputText(cs); text != "" {
+		logUtils.Screen(text)
+	}
+}
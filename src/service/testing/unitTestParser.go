@@ -0,0 +1,212 @@
+package testingService
+
+import (
+	"encoding/xml"
+	"github.com/easysoft/zentaoatf/src/model"
+	constant "github.com/easysoft/zentaoatf/src/utils/const"
+	stringUtils "github.com/easysoft/zentaoatf/src/utils/string"
+	"strings"
+)
+
+// UnitTestParser converts result files of a unit test framework to suites.
+// in-house formats can be added by calling RegisterUnitTestParser in init of another package.
+type UnitTestParser struct {
+	Name    string
+	Exts    []string                  // extensions of result files read from a dir, .xml if empty
+	Detect  func(content string) bool // nil if the format can't be detected in auto mode
+	Convert func(file string, content string) ([]model.UnitTestSuite, error)
+
+	// combines the suites of all result files, which are also read from sub dirs, such as outputs of parallel runs
+	Merge func(suites []model.UnitTestSuite) []model.UnitTestSuite
+}
+
+var unitTestParsers = make([]UnitTestParser, 0)
+
+// RegisterUnitTestParser adds a parser, or replaces the one with the same name
+func RegisterUnitTestParser(parser UnitTestParser) {
+	if len(parser.Exts) == 0 {
+		parser.Exts = []string{".xml"}
+	}
+
+	for idx, item := range unitTestParsers {
+		if item.Name == parser.Name {
+			unitTestParsers[idx] = parser
+			return
+		}
+	}
+
+	unitTestParsers = append(unitTestParsers, parser)
+}
+
+func GetUnitTestParser(name string) (parser UnitTestParser, ok bool) {
+	for _, item := range unitTestParsers {
+		if item.Name == name {
+			return item, true
+		}
+	}
+
+	return
+}
+
+// GetUnitTestTypes returns names of registered parsers and auto, which can be used as unit test type
+func GetUnitTestTypes() (types []string) {
+	for _, item := range unitTestParsers {
+		types = append(types, item.Name)
+	}
+
+	return append(types, constant.UnitTestTypeAuto)
+}
+
+func IsUnitTestType(name string) bool {
+	return stringUtils.FindInArr(name, GetUnitTestTypes())
+}
+
+// DetectUnitTestType inspects a result file to pick the parser, return empty if it's unknown.
+// parsers registered later are checked first, so that in-house formats go before the general built-in ones.
+func DetectUnitTestType(content string) string {
+	for i := len(unitTestParsers) - 1; i >= 0; i-- {
+		parser := unitTestParsers[i]
+		if parser.Detect != nil && parser.Detect(content) {
+			return parser.Name
+		}
+	}
+
+	return ""
+}
+
+// extensions of result files to read for a type, all of them in auto mode
+func getUnitResultExts(testType string) (exts []string) {
+	for _, item := range unitTestParsers {
+		if item.Name != testType && testType != constant.UnitTestTypeAuto {
+			continue
+		}
+
+		for _, ext := range item.Exts {
+			if !stringUtils.FindInArr(ext, exts) {
+				exts = append(exts, ext)
+			}
+		}
+	}
+
+	return
+}
+
+func init() {
+	convertJunit := func(file string, content string) ([]model.UnitTestSuite, error) {
+		testSuite := model.UnitTestSuite{}
+		err := xml.Unmarshal([]byte(content), &testSuite)
+		return []model.UnitTestSuite{testSuite}, err
+	}
+
+	RegisterUnitTestParser(UnitTestParser{Name: constant.UnitTestTypeJunit, Convert: convertJunit,
+		Detect: detectXmlResult(func(info xmlResultInfo) bool {
+			return info.Root == "testsuite" && !isQTestResult(info)
+		})})
+	RegisterUnitTestParser(UnitTestParser{Name: constant.UnitTestTypeTestNG, Convert: convertJunit})
+
+	RegisterUnitTestParser(UnitTestParser{Name: constant.UnitTestTypeRobot, Merge: mergeRobotResults,
+		Detect: detectXmlResult(func(info xmlResultInfo) bool { return info.Root == "robot" }),
+		Convert: func(file string, content string) ([]model.UnitTestSuite, error) {
+			result := model.RobotResult{}
+			err := xml.Unmarshal([]byte(content), &result)
+			return []model.UnitTestSuite{ConvertRobotResult(result)}, err
+		}})
+
+	RegisterUnitTestParser(UnitTestParser{Name: constant.UnitTestTypeCypress,
+		Detect: detectXmlResult(isCypressResult),
+		Convert: func(file string, content string) ([]model.UnitTestSuite, error) {
+			result := model.CypressTestsuites{}
+			err := xml.Unmarshal([]byte(content), &result)
+			return []model.UnitTestSuite{ConvertCyResult(result)}, err
+		}})
+
+	RegisterUnitTestParser(UnitTestParser{Name: "phpunit",
+		Detect: detectXmlResult(func(info xmlResultInfo) bool { return info.Root == "tests" }),
+		Convert: func(file string, content string) ([]model.UnitTestSuite, error) {
+			result := model.PhpUnitSuites{}
+			err := xml.Unmarshal([]byte(content), &result)
+			return []model.UnitTestSuite{ConvertPhpUnitResult(result)}, err
+		}})
+
+	RegisterUnitTestParser(UnitTestParser{Name: "pytest",
+		Detect: detectXmlResult(isPyTestResult),
+		Convert: func(file string, content string) ([]model.UnitTestSuite, error) {
+			result := model.PyTestSuites{}
+			err := xml.Unmarshal([]byte(content), &result)
+			return []model.UnitTestSuite{ConvertPyTestResult(result)}, err
+		}})
+
+	RegisterUnitTestParser(UnitTestParser{Name: "jest",
+		Detect: detectXmlResult(isJestResult),
+		Convert: func(file string, content string) ([]model.UnitTestSuite, error) {
+			result := model.JestSuites{}
+			err := xml.Unmarshal([]byte(content), &result)
+			return []model.UnitTestSuite{ConvertJestResult(result)}, err
+		}})
+
+	RegisterUnitTestParser(UnitTestParser{Name: "cppunit",
+		Detect: detectXmlResult(func(info xmlResultInfo) bool {
+			return info.Root == "TestRun" && info.RootSpace != trxNamespace // root of both trx and cppunit
+		}),
+		Convert: func(file string, content string) ([]model.UnitTestSuite, error) {
+			content = strings.Replace(content, "ISO-8859-1", "UTF-8", -1)
+
+			result := model.CppUnitSuites{}
+			err := xml.Unmarshal([]byte(content), &result)
+			return []model.UnitTestSuite{ConvertCppUnitResult(result)}, err
+		}})
+
+	RegisterUnitTestParser(UnitTestParser{Name: "gtest",
+		Detect: detectXmlResult(isGTestResult),
+		Convert: func(file string, content string) ([]model.UnitTestSuite, error) {
+			result := model.GTestSuites{}
+			err := xml.Unmarshal([]byte(content), &result)
+			return []model.UnitTestSuite{ConvertGTestResult(result)}, err
+		}})
+
+	RegisterUnitTestParser(UnitTestParser{Name: "qtest",
+		Detect: detectXmlResult(isQTestResult),
+		Convert: func(file string, content string) ([]model.UnitTestSuite, error) {
+			result := model.QTestSuites{}
+			err := xml.Unmarshal([]byte(content), &result)
+			return []model.UnitTestSuite{ConvertQTestResult(result)}, err
+		}})
+
+	RegisterUnitTestParser(UnitTestParser{Name: constant.UnitTestTypeGoTest, Exts: []string{".json"},
+		Detect: isGoTestResult,
+		Convert: func(file string, content string) ([]model.UnitTestSuite, error) {
+			return ConvertGoTestResult(strings.Split(content, "\n")), nil
+		}})
+
+	RegisterUnitTestParser(UnitTestParser{Name: constant.UnitTestTypeNUnit,
+		Detect: detectXmlResult(func(info xmlResultInfo) bool { return info.Root == "test-run" }),
+		Convert: func(file string, content string) ([]model.UnitTestSuite, error) {
+			result := model.NUnitTestRun{}
+			err := xml.Unmarshal([]byte(content), &result)
+			return []model.UnitTestSuite{ConvertNUnitResult(result)}, err
+		}})
+
+	RegisterUnitTestParser(UnitTestParser{Name: constant.UnitTestTypeXUnit,
+		Detect: detectXmlResult(func(info xmlResultInfo) bool { return info.Root == "assemblies" }),
+		Convert: func(file string, content string) ([]model.UnitTestSuite, error) {
+			result := model.XUnitAssemblies{}
+			err := xml.Unmarshal([]byte(content), &result)
+			return []model.UnitTestSuite{ConvertXUnitResult(result)}, err
+		}})
+
+	RegisterUnitTestParser(UnitTestParser{Name: constant.UnitTestTypeMSTest, Exts: []string{".trx"},
+		Detect: detectXmlResult(func(info xmlResultInfo) bool {
+			return info.Root == "TestRun" && info.RootSpace == trxNamespace
+		}),
+		Convert: func(file string, content string) ([]model.UnitTestSuite, error) {
+			result := model.TrxTestRun{}
+			err := xml.Unmarshal([]byte(content), &result)
+			return []model.UnitTestSuite{ConvertTrxResult(result)}, err
+		}})
+
+	RegisterUnitTestParser(UnitTestParser{Name: constant.UnitTestTypeTap, Exts: []string{".tap"},
+		Detect: isTapResult,
+		Convert: func(file string, content string) ([]model.UnitTestSuite, error) {
+			return []model.UnitTestSuite{ConvertTapResult(strings.Split(content, "\n"), getTapSuiteName(file))}, nil
+		}})
+}
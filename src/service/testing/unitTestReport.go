@@ -41,6 +41,24 @@ func GenUnitTestReport(cases []model.UnitResult, classNameMaxWidth int, timeVal
 			failedCaseLinesDesc = append(failedCaseLinesDesc, line)
 			failDesc := fmt.Sprintf("   %s - %s", cs.Failure.Type, cs.Failure.Desc)
 			failedCaseLinesDesc = append(failedCaseLinesDesc, failDesc)
+			if output := GetUnitOutputText(cs); output != "" {
+				failedCaseLinesDesc = append(failedCaseLinesDesc, output)
+			}
+		} else if cs.Error != nil {
+			report.Error++
+
+			className := cases[idx].TestSuite
+
+			line := fmt.Sprintf("[%s] %d.%s (%s)", className, cs.Id, cs.Title, i118Utils.I118Prt.Sprintf("error"))
+			failedCaseLines = append(failedCaseLines, line)
+
+			failedCaseLinesDesc = append(failedCaseLinesDesc, line)
+			failedCaseLinesDesc = append(failedCaseLinesDesc, fmt.Sprintf("   %s - %s", cs.Error.Type, cs.Error.Desc))
+			if output := GetUnitOutputText(cs); output != "" {
+				failedCaseLinesDesc = append(failedCaseLinesDesc, output)
+			}
+		} else if cs.Skipped != nil {
+			report.Skip++
 		} else {
 			report.Pass++
 		}
@@ -81,13 +99,18 @@ func GenUnitTestReport(cases []model.UnitResult, classNameMaxWidth int, timeVal
 		statusColor := logUtils.ColoredStatus(cs.Status)
 		testSuite := stringUtils.AddPostfix(cs.TestSuite, classNameMaxWidth, " ")
 
+		title := cs.Title
+		if cs.Cid > 0 { // mapped to zentao case
+			title += fmt.Sprintf(" #%d", cs.Cid)
+		}
+
 		format := "(%" + width + "d/%d) %s [%s] [%" + width + "d. %s] (%.3fs)"
-		logUtils.Screen(fmt.Sprintf(format, idx+1, report.Total, statusColor, testSuite, cs.Id, cs.Title, cs.Duration))
+		logUtils.Screen(fmt.Sprintf(format, idx+1, report.Total, statusColor, testSuite, cs.Id, title, cs.Duration))
 		logUtils.Result(fmt.Sprintf(format, idx+1, report.Total,
-			i118Utils.I118Prt.Sprintf(cs.Status), testSuite, cs.Id, cs.Title, cs.Duration))
+			i118Utils.I118Prt.Sprintf(cs.Status), testSuite, cs.Id, title, cs.Duration))
 	}
 
-	if report.Fail > 0 {
+	if report.Fail > 0 || report.Error > 0 {
 		logUtils.ScreenAndResult("\n" + i118Utils.I118Prt.Sprintf("failed_scripts"))
 		logUtils.Screen(strings.Join(failedCaseLines, "\n"))
 		logUtils.Result(strings.Join(failedCaseLinesDesc, "\n"))
@@ -103,11 +126,18 @@ func GenUnitTestReport(cases []model.UnitResult, classNameMaxWidth int, timeVal
 	failStr := fmt.Sprintf(fmtStr, report.Fail, float32(report.Fail*100/report.Total), i118Utils.I118Prt.Sprintf("fail"))
 	skipStr := fmt.Sprintf(fmtStr, report.Skip, float32(report.Skip*100/report.Total), i118Utils.I118Prt.Sprintf("skip"))
 
+	// errors in test setup are shown separately from the failures
+	errorStr := ""
+	if report.Error > 0 {
+		errorStr = fmt.Sprintf(fmtStr, report.Error, float32(report.Error*100/report.Total), i118Utils.I118Prt.Sprintf("error"))
+		errorStr = i118Utils.I118Prt.Sprintf("with_errors", errorStr)
+	}
+
 	// 输出到文件
 	logUtils.Result("\n" + time.Now().Format("2006-01-02 15:04:05") + " " +
 		i118Utils.I118Prt.Sprintf("run_scripts",
 			report.Total, report.Duration, secTag,
-			passStr, failStr, skipStr,
+			passStr, failStr+errorStr, skipStr,
 			" "+vari.LogDir+"result.txt ",
 		))
 
@@ -115,12 +145,13 @@ func GenUnitTestReport(cases []model.UnitResult, classNameMaxWidth int, timeVal
 	logUtils.Screen("\n" + time.Now().Format("2006-01-02 15:04:05") + " " +
 		i118Utils.I118Prt.Sprintf("run_scripts",
 			report.Total, report.Duration, secTag,
-			color.GreenString(passStr), color.RedString(failStr), color.YellowString(skipStr),
+			color.GreenString(passStr), color.RedString(failStr)+color.MagentaString(errorStr), color.YellowString(skipStr),
 			" "+vari.LogDir+"result.txt ",
 		))
 
 	json, _ := json.Marshal(report)
 	fileUtils.WriteFile(vari.LogDir+"result.json", string(json))
+	SaveToHistory(report)
 
 	return report
 }
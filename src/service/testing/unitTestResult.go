@@ -1,47 +1,50 @@
 package testingService
 
 import (
-	"encoding/xml"
-	"fmt"
+	"encoding/json"
 	"github.com/easysoft/zentaoatf/src/model"
 	constant "github.com/easysoft/zentaoatf/src/utils/const"
 	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
+	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
 	logUtils "github.com/easysoft/zentaoatf/src/utils/log"
+	stringUtils "github.com/easysoft/zentaoatf/src/utils/string"
 	"github.com/easysoft/zentaoatf/src/utils/vari"
+	"github.com/fatih/color"
 	"github.com/mattn/go-runewidth"
 	"io/ioutil"
+	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"time"
 )
 
-func RetrieveUnitResult() (suites []model.UnitTestSuite, resultDir string) {
+// RetrieveUnitResult converts result files in dir by the parser of unit test type,
+// or by the parser detected for each file in auto mode.
+func RetrieveUnitResult(resultDir string) (suites []model.UnitTestSuite) {
 	resultFiles := make([]string, 0)
 
-	if vari.UnitTestType == constant.UnitTestTypeJunit && vari.UnitTestTool == constant.UnitTestToolMvn {
-		resultDir = fmt.Sprintf("target%ssurefire-reports%s", constant.PthSep, constant.PthSep)
-	} else if vari.UnitTestType == constant.UnitTestTypeTestNG && vari.UnitTestTool == constant.UnitTestToolMvn {
-		resultDir = fmt.Sprintf("target%ssurefire-reports%sjunitreports", constant.PthSep, constant.PthSep)
-	} else if vari.UnitTestType == constant.UnitTestTypeRobot || vari.UnitTestType == constant.UnitTestTypeCypress {
-		resultDir = vari.UnitTestResults
-	} else {
-		resultDir = vari.UnitTestResult
-	}
-
-	if vari.ServerProjectDir != "" {
-		resultDir = vari.ServerProjectDir + resultDir
-	}
-
 	if fileUtils.IsDir(resultDir) {
 		resultDir = fileUtils.AddPathSepIfNeeded(resultDir)
-
-		dir, err := ioutil.ReadDir(resultDir)
-		if err == nil {
-			for _, fi := range dir {
-				name := fi.Name()
-				ext := path.Ext(name)
-				if ext == ".xml" {
-					resultFiles = append(resultFiles, resultDir+name)
+		exts := getUnitResultExts(vari.UnitTestType)
+		parser, _ := GetUnitTestParser(vari.UnitTestType)
+
+		// results of different frameworks or parallel runs may be in sub dirs
+		if vari.UnitTestType == constant.UnitTestTypeAuto || parser.Merge != nil {
+			filepath.Walk(resultDir, func(pth string, fi os.FileInfo, err error) error {
+				if err == nil && !fi.IsDir() && stringUtils.FindInArr(path.Ext(pth), exts) {
+					resultFiles = append(resultFiles, pth)
+				}
+				return nil
+			})
+		} else {
+			dir, err := ioutil.ReadDir(resultDir)
+			if err == nil {
+				for _, fi := range dir {
+					name := fi.Name()
+					if stringUtils.FindInArr(path.Ext(name), exts) {
+						resultFiles = append(resultFiles, resultDir+name)
+					}
 				}
 			}
 		}
@@ -49,77 +52,66 @@ func RetrieveUnitResult() (suites []model.UnitTestSuite, resultDir string) {
 		resultFiles = append(resultFiles, resultDir)
 	}
 
+	testTypes := make([]string, 0)
+	typeSuites := map[string][]model.UnitTestSuite{}
 	for _, file := range resultFiles {
 		content := fileUtils.ReadFile(file)
 
-		var err error
-		var testSuite model.UnitTestSuite
+		testType := vari.UnitTestType
+		if testType == constant.UnitTestTypeAuto {
+			testType = DetectUnitTestType(content)
+			if testType == "" {
+				logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("unknown_unit_result_format", file), color.FgYellow)
+				continue
+			}
+		}
 
-		if vari.UnitTestType == "junit" || vari.UnitTestType == "testng" {
-			testSuite = model.UnitTestSuite{}
-			err = xml.Unmarshal([]byte(content), &testSuite)
+		parser, ok := GetUnitTestParser(testType)
+		if !ok {
+			continue
+		}
 
-		} else if vari.UnitTestType == "phpunit" {
-			phpTestSuite := model.PhpUnitSuites{}
-			err = xml.Unmarshal([]byte(content), &phpTestSuite)
-			if err == nil {
-				testSuite = ConvertPhpUnitResult(phpTestSuite)
-			}
-		} else if vari.UnitTestType == "pytest" {
-			pyTestSuite := model.PyTestSuites{}
-			err = xml.Unmarshal([]byte(content), &pyTestSuite)
-			if err == nil {
-				testSuite = ConvertPyTestResult(pyTestSuite)
-			}
-		} else if vari.UnitTestType == "jest" {
-			jestSuite := model.JestSuites{}
-			err = xml.Unmarshal([]byte(content), &jestSuite)
-			if err == nil {
-				testSuite = ConvertJestResult(jestSuite)
-			}
-		} else if vari.UnitTestType == "gtest" {
-			gTestSuite := model.GTestSuites{}
-			err = xml.Unmarshal([]byte(content), &gTestSuite)
-			if err == nil {
-				testSuite = ConvertGTestResult(gTestSuite)
-			}
-		} else if vari.UnitTestType == "qtest" {
-			qTestSuite := model.QTestSuites{}
-			err = xml.Unmarshal([]byte(content), &qTestSuite)
-			if err == nil {
-				testSuite = ConvertQTestResult(qTestSuite)
-			}
-		} else if vari.UnitTestType == "cppunit" {
-			content = strings.Replace(content, "ISO-8859-1", "UTF-8", -1)
+		fileSuites, err := parser.Convert(file, content)
+		if err != nil {
+			continue
+		}
 
-			cppUnitSuites := model.CppUnitSuites{}
-			err = xml.Unmarshal([]byte(content), &cppUnitSuites)
-			if err == nil {
-				testSuite = ConvertCppUnitResult(cppUnitSuites)
-			}
-		} else if vari.UnitTestType == "robot" {
-			robotResult := model.RobotResult{}
-			err = xml.Unmarshal([]byte(content), &robotResult)
-			if err == nil {
-				testSuite = ConvertRobotResult(robotResult)
-			}
-		} else if vari.UnitTestType == "cypress" {
-			cyResult := model.CypressTestsuites{}
-			err = xml.Unmarshal([]byte(content), &cyResult)
-			if err == nil {
-				testSuite = ConvertCyResult(cyResult)
-			}
+		if _, ok := typeSuites[testType]; !ok {
+			testTypes = append(testTypes, testType)
 		}
+		typeSuites[testType] = append(typeSuites[testType], fileSuites...)
+	}
 
-		if err == nil {
-			suites = append(suites, testSuite)
+	for _, testType := range testTypes {
+		parser, _ := GetUnitTestParser(testType)
+		if parser.Merge != nil {
+			typeSuites[testType] = parser.Merge(typeSuites[testType])
 		}
+
+		suites = append(suites, typeSuites[testType]...)
 	}
 
 	return
 }
 
+// RetrieveGoTestResult reads go test -json events from the result file if provided, or from the output of command
+func RetrieveGoTestResult(output []string) (suites []model.UnitTestSuite, resultDir string) {
+	resultDir = vari.UnitTestResult
+	if vari.ServerProjectDir != "" {
+		resultDir = vari.ServerProjectDir + resultDir
+	}
+
+	if !fileUtils.IsDir(resultDir) && fileUtils.FileExist(resultDir) {
+		output = strings.Split(fileUtils.ReadFile(resultDir), "\n")
+	}
+
+	suites = ConvertGoTestResult(output)
+	return
+}
+
 func ParserUnitTestResult(testSuites []model.UnitTestSuite) (cases []model.UnitResult, classNameMaxWidth int, dur float32) {
+	caseMap := readUnitCaseMap(vari.UnitTestCaseMap)
+
 	idx := 1
 	for _, suite := range testSuites {
 		if suite.Time != 0 { // for junit, there is a time on suite level
@@ -128,6 +120,8 @@ func ParserUnitTestResult(testSuites []model.UnitTestSuite) (cases []model.UnitR
 
 		for _, cs := range suite.TestCases {
 			cs.Id = idx
+			cs.Cid = getUnitCaseId(cs, caseMap)
+			cs.Attachments = getUnitAttachments(cs)
 
 			if cs.Failure != nil {
 				cs.Status = "fail"
@@ -135,6 +129,16 @@ func ParserUnitTestResult(testSuites []model.UnitTestSuite) (cases []model.UnitR
 				cs.Failure.Desc = strings.Replace(cs.Failure.Desc, "<![CDATA[", "", -1)
 				cs.Failure.Desc = strings.Replace(cs.Failure.Desc, "]]>", "", -1)
 				logUtils.Screen(cs.Failure.Desc)
+				printUnitOutput(cs)
+			} else if cs.Error != nil {
+				cs.Status = constant.ERROR.String()
+
+				cs.Error.Desc = strings.Replace(cs.Error.Desc, "<![CDATA[", "", -1)
+				cs.Error.Desc = strings.Replace(cs.Error.Desc, "]]>", "", -1)
+				logUtils.Screen(cs.Error.Desc)
+				printUnitOutput(cs)
+			} else if cs.Skipped != nil {
+				cs.Status = "skip"
 			} else {
 				cs.Status = "pass"
 			}
@@ -169,6 +173,11 @@ func ConvertJestResult(jestSuite model.JestSuites) model.UnitTestSuite {
 			}
 
 			caseResult.Failure = cs.Failure
+			caseResult.Error = cs.Error
+			caseResult.Skipped = cs.Skipped
+			caseResult.SystemOut = cs.SystemOut
+			caseResult.SystemErr = cs.SystemErr
+			caseResult.Properties = cs.Properties
 
 			testSuite.TestCases = append(testSuite.TestCases, caseResult)
 		}
@@ -194,7 +203,12 @@ func ConvertPhpUnitResult(phpUnitSuite model.PhpUnitSuites) model.UnitTestSuite
 			caseResult.TestSuite = cs.TestSuite
 		}
 
-		if cs.Status != 0 {
+		// status of phpunit: 0 passed, 1 skipped, 2 incomplete, 3 failure, 4 error, 5 risky, 6 warning
+		if cs.Status == 1 || cs.Status == 2 {
+			caseResult.Skipped = &model.Skipped{Message: cs.Fail}
+		} else if cs.Status == 4 {
+			caseResult.Error = &model.Failure{Desc: cs.Fail}
+		} else if cs.Status != 0 {
 			fail := model.Failure{}
 			fail.Desc = cs.Fail
 			caseResult.Failure = &fail
@@ -219,6 +233,8 @@ func ConvertPyTestResult(pytestSuites model.PyTestSuites) model.UnitTestSuite {
 			caseResult := model.UnitResult{}
 			caseResult.Title = cs.Title
 			caseResult.Duration = cs.Duration
+			caseResult.SystemOut = cs.SystemOut
+			caseResult.SystemErr = cs.SystemErr
 
 			if suite.Title != "" && suite.Title != "pytest" {
 				caseResult.TestSuite = suite.Title
@@ -235,7 +251,9 @@ func ConvertPyTestResult(pytestSuites model.PyTestSuites) model.UnitTestSuite {
 				fail := model.Failure{}
 				fail.Type = cs.Error.Message
 				fail.Desc = cs.Error.Text
-				caseResult.Failure = &fail
+				caseResult.Error = &fail
+			} else if cs.Skipped != nil {
+				caseResult.Skipped = &model.Skipped{Message: cs.Skipped.Message}
 			}
 
 			testSuite.TestCases = append(testSuite.TestCases, caseResult)
@@ -269,6 +287,10 @@ func ConvertGTestResult(gTestSuite model.GTestSuites) model.UnitTestSuite {
 				fail.Type = cs.Failure.Type
 				fail.Desc = cs.Failure.Desc
 				caseResult.Failure = &fail
+			} else if cs.Skipped != nil {
+				caseResult.Skipped = &model.Skipped{Message: cs.Skipped.Message}
+			} else if cs.Status == "notrun" || cs.Result == "skipped" || cs.Result == "suppressed" {
+				caseResult.Skipped = &model.Skipped{Message: cs.Result}
 			}
 
 			testSuite.TestCases = append(testSuite.TestCases, caseResult)
@@ -290,7 +312,11 @@ func ConvertCppUnitResult(cppunitSuite model.CppUnitSuites) model.UnitTestSuite
 		fail := model.Failure{}
 		fail.Type = cs.FailureType
 		fail.Desc = cs.Message
-		caseResult.Failure = &fail
+		if cs.FailureType == "Error" { // uncaught exception, others are Assertion
+			caseResult.Error = &fail
+		} else {
+			caseResult.Failure = &fail
+		}
 
 		testSuite.TestCases = append(testSuite.TestCases, caseResult)
 	}
@@ -320,6 +346,8 @@ func ConvertQTestResult(qTestSuite model.QTestSuites) model.UnitTestSuite {
 			fail.Type = cs.Failure.Type
 			fail.Desc = cs.Failure.Desc
 			caseResult.Failure = &fail
+		} else if cs.Result == "skip" || cs.Result == "blacklisted" {
+			caseResult.Skipped = &model.Skipped{Message: cs.Result}
 		}
 
 		testSuite.TestCases = append(testSuite.TestCases, caseResult)
@@ -328,58 +356,6 @@ func ConvertQTestResult(qTestSuite model.QTestSuites) model.UnitTestSuite {
 	return testSuite
 }
 
-func ConvertRobotResult(result model.RobotResult) model.UnitTestSuite {
-	testSuite := model.UnitTestSuite{}
-
-	suiteMap := map[string]string{}
-	for _, state := range result.Statistics.Suite.States {
-		suiteMap[state.ID] = state.Text
-	}
-
-	tests := make([]model.RobotTest, 0)
-	for _, suite := range result.Suites {
-		RetrieveRobotTests(suite, &tests)
-	}
-
-	for _, cs := range tests {
-		caseResult := model.UnitResult{}
-		caseResult.Title = cs.Name
-		caseResult.Status = strings.ToLower(cs.Status.Status)
-
-		suiteId := cs.ID[0:strings.LastIndex(cs.ID, "-")]
-		caseResult.TestSuite = suiteMap[suiteId]
-
-		templ := "20060102 15:04:05.000"
-		startTime, _ := time.ParseInLocation(templ, cs.Status.StartTime, time.Local)
-		endTime, _ := time.ParseInLocation(templ, cs.Status.EndTime, time.Local)
-
-		caseResult.StartTime = startTime.Unix()
-		caseResult.EndTime = endTime.Unix()
-		caseResult.Duration = float32(caseResult.EndTime - caseResult.StartTime)
-
-		if caseResult.Status != "pass" {
-			fail := model.Failure{}
-			fail.Type = ""
-			fail.Desc = cs.Status.Text
-			caseResult.Failure = &fail
-		}
-
-		testSuite.TestCases = append(testSuite.TestCases, caseResult)
-	}
-
-	return testSuite
-}
-
-func RetrieveRobotTests(suite model.RobotSuite, tests *[]model.RobotTest) {
-	for _, suite := range suite.Suites {
-		RetrieveRobotTests(suite, tests)
-	}
-
-	for _, test := range suite.Tests {
-		*tests = append(*tests, test)
-	}
-}
-
 func ConvertCyResult(result model.CypressTestsuites) model.UnitTestSuite {
 	testSuite := model.UnitTestSuite{}
 
@@ -401,6 +377,8 @@ func ConvertCyResult(result model.CypressTestsuites) model.UnitTestSuite {
 			caseResult.TestSuite = suite.Name
 			caseResult.Title = cs.Name
 			caseResult.Duration = float32(cs.Time)
+			caseResult.SystemOut = cs.SystemOut
+			caseResult.SystemErr = cs.SystemErr
 
 			if len(cs.Failures) > 0 {
 				caseResult.Status = "fail"
@@ -409,6 +387,9 @@ func ConvertCyResult(result model.CypressTestsuites) model.UnitTestSuite {
 				fail.Type = cs.Failures[0].Type
 				fail.Desc = cs.Failures[0].Message
 				caseResult.Failure = &fail
+			} else if cs.Skipped != nil {
+				caseResult.Status = "skip"
+				caseResult.Skipped = &model.Skipped{}
 			} else {
 				caseResult.Status = "pass"
 			}
@@ -419,3 +400,131 @@ func ConvertCyResult(result model.CypressTestsuites) model.UnitTestSuite {
 
 	return testSuite
 }
+
+// ConvertGoTestResult converts the event stream of go test -json, each package is a suite,
+// subtests like TestLogin/admin are kept as separate cases.
+func ConvertGoTestResult(lines []string) (suites []model.UnitTestSuite) {
+	suiteMap := map[string]*model.UnitTestSuite{}
+	pkgs := make([]string, 0)
+	outputs := map[string][]string{}
+
+	for _, line := range lines {
+		event := model.GoTestEvent{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &event); err != nil || event.Package == "" {
+			continue
+		}
+
+		suite, ok := suiteMap[event.Package]
+		if !ok {
+			suite = &model.UnitTestSuite{Name: event.Package}
+			suiteMap[event.Package] = suite
+			pkgs = append(pkgs, event.Package)
+		}
+
+		key := event.Package + " " + event.Test
+		switch event.Action {
+		case "output":
+			outputs[key] = append(outputs[key], event.Output)
+
+		case "pass", "fail", "skip":
+			if event.Test == "" {
+				suite.Time = float32(event.Elapsed)
+
+				// package failed without any failed test, such as build error or panic in init
+				if event.Action == "fail" && suite.Failures == 0 {
+					caseResult := model.UnitResult{TestSuite: event.Package, Title: event.Package,
+						Duration: float32(event.Elapsed)}
+					caseResult.Error = &model.Failure{Type: "error", Desc: strings.Join(outputs[key], "")}
+					suite.TestCases = append(suite.TestCases, caseResult)
+					suite.Failures++
+				}
+				continue
+			}
+
+			caseResult := model.UnitResult{TestSuite: event.Package, Title: event.Test,
+				Duration: float32(event.Elapsed)}
+			if !event.Time.IsZero() {
+				caseResult.EndTime = event.Time.Unix()
+				caseResult.StartTime = event.Time.Add(-time.Duration(event.Elapsed * float64(time.Second))).Unix()
+			}
+
+			if event.Action == "fail" {
+				caseResult.Failure = &model.Failure{Type: "fail", Desc: strings.Join(outputs[key], "")}
+				suite.Failures++
+			} else if event.Action == "skip" {
+				caseResult.Skipped = &model.Skipped{Message: strings.TrimSpace(strings.Join(outputs[key], ""))}
+				suite.Skipped++
+			}
+
+			suite.TestCases = append(suite.TestCases, caseResult)
+			suite.Tests++
+		}
+	}
+
+	for _, pkg := range pkgs {
+		suite := suiteMap[pkg]
+		removeGoParentTests(suite)
+
+		if len(suite.TestCases) > 0 {
+			suites = append(suites, *suite)
+		}
+	}
+
+	return
+}
+
+// a test with subtests fails if any subtest failed, only the subtests are reported, so that a failure isn't counted twice,
+// the parent is kept if it failed while all subtests passed.
+func removeGoParentTests(suite *model.UnitTestSuite) {
+	parents := map[string]bool{}
+	failedParents := map[string]bool{}
+	for _, cs := range suite.TestCases {
+		for name := cs.Title; strings.Contains(name, "/"); {
+			name = name[:strings.LastIndex(name, "/")]
+			parents[name] = true
+			if cs.Failure != nil {
+				failedParents[name] = true
+			}
+		}
+	}
+
+	cases := make([]model.UnitResult, 0)
+	suite.Tests, suite.Failures, suite.Skipped = 0, 0, 0
+	for _, cs := range suite.TestCases {
+		if parents[cs.Title] && (cs.Failure == nil || failedParents[cs.Title]) {
+			continue
+		}
+
+		cases = append(cases, cs)
+		suite.Tests++
+		if cs.Failure != nil || cs.Error != nil {
+			suite.Failures++
+		} else if cs.Skipped != nil {
+			suite.Skipped++
+		}
+	}
+	suite.TestCases = cases
+}
+
+// GetGoTestOutput returns the text to print for a line of go test -json,
+// which is the output of test, instead of the json event
+func GetGoTestOutput(line string) (output string, ok bool) {
+	event := model.GoTestEvent{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &event); err != nil {
+		return strings.TrimRight(line, "\n"), true
+	}
+
+	if event.Action != "output" {
+		return
+	}
+	return strings.TrimRight(event.Output, "\n"), true
+}
+
+// GetGoTestCmd adds -json to go test command if needed
+func GetGoTestCmd(cmdStr string) string {
+	if strings.Contains(cmdStr, " -json") {
+		return cmdStr
+	}
+
+	return strings.Replace(cmdStr, "go test", "go test -json", 1)
+}
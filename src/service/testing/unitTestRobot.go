@@ -0,0 +1,178 @@
+package testingService
+
+import (
+	"github.com/easysoft/zentaoatf/src/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConvertRobotResult converts output.xml of robot framework, the suite of a test is its long name like Tests.Login.
+// tags of a test are kept, and its keywords are kept as steps.
+func ConvertRobotResult(result model.RobotResult) model.UnitTestSuite {
+	testSuite := model.UnitTestSuite{}
+
+	for _, suite := range result.Suites {
+		retrieveRobotTests(suite, "", &testSuite)
+	}
+
+	return testSuite
+}
+
+func retrieveRobotTests(suite model.RobotSuite, parentName string, testSuite *model.UnitTestSuite) {
+	name := suite.Name
+	if parentName != "" {
+		name = parentName + "." + name
+	}
+
+	for _, child := range suite.Suites {
+		retrieveRobotTests(child, name, testSuite)
+	}
+
+	for _, cs := range suite.Tests {
+		testSuite.TestCases = append(testSuite.TestCases, convertRobotTest(cs, name))
+	}
+}
+
+func convertRobotTest(cs model.RobotTest, suiteName string) model.UnitResult {
+	caseResult := model.UnitResult{}
+	caseResult.TestSuite = suiteName
+	caseResult.Title = cs.Name
+	caseResult.Status = strings.ToLower(cs.Status.Status)
+	caseResult.Tags = append(append([]string{}, cs.OldTags...), cs.Tags...)
+	caseResult.Steps = getRobotSteps(cs.Keywords, "", false)
+
+	startTime, endTime := getRobotTime(cs.Status)
+	caseResult.StartTime = startTime.Unix()
+	caseResult.EndTime = endTime.Unix()
+	caseResult.Duration = float32(endTime.Sub(startTime).Seconds())
+
+	if caseResult.Status == "skip" || caseResult.Status == "not run" {
+		caseResult.Skipped = &model.Skipped{Message: cs.Status.Text}
+	} else if caseResult.Status != "pass" {
+		fail := model.Failure{}
+		fail.Type = ""
+		fail.Desc = cs.Status.Text
+		caseResult.Failure = &fail
+	}
+
+	return caseResult
+}
+
+// getRobotSteps returns a step for each keyword, and the failed one is followed by its failed children,
+// so the chain of keywords leading to a failure is kept, with ids like 2, 2.1 and 2.1.3.
+func getRobotSteps(keywords []model.RobotKeyword, parentId string, onlyFailed bool) (steps []model.StepLog) {
+	idx := 0
+	for _, kw := range keywords {
+		if kw.Status.Status == "" { // elements like timeout and assign are not keywords
+			continue
+		}
+		idx++
+
+		passed := kw.Status.Status != "FAIL"
+		if kw.Status.Status == "NOT RUN" || (onlyFailed && passed) {
+			continue
+		}
+
+		id := strconv.Itoa(idx)
+		if parentId != "" {
+			id = parentId + "." + id
+		}
+
+		step := model.StepLog{Id: id, Name: getRobotKeywordName(kw), Status: passed}
+		if !passed {
+			if msg := getRobotFailMessage(kw); msg != "" {
+				step.CheckPoints = []model.CheckPointLog{{Numb: 1, Actual: msg, Status: false}}
+			}
+		}
+		steps = append(steps, step)
+
+		if !passed {
+			steps = append(steps, getRobotSteps(kw.Keywords, id, true)...)
+		}
+	}
+
+	return
+}
+
+// name like SeleniumLibrary.Input Text  id=username  admin, type is added for setup and control structures
+func getRobotKeywordName(kw model.RobotKeyword) string {
+	name := kw.Name
+
+	library := kw.Library
+	if library == "" {
+		library = kw.Owner
+	}
+	if library != "" {
+		name = library + "." + name
+	}
+
+	typ := kw.Type
+	if kw.XMLName.Local != "kw" {
+		typ = kw.XMLName.Local
+	}
+	if typ != "" && !strings.EqualFold(typ, "kw") && !strings.EqualFold(typ, "keyword") {
+		name = strings.TrimSpace(strings.ToUpper(typ) + " " + name)
+	}
+
+	args := append(append([]string{}, kw.OldArgs...), kw.Args...)
+	if len(args) > 0 {
+		name += "  " + strings.Join(args, "  ")
+	}
+
+	return name
+}
+
+func getRobotFailMessage(kw model.RobotKeyword) string {
+	msgs := make([]string, 0)
+	for _, msg := range kw.Msgs {
+		if msg.Level == "FAIL" {
+			msgs = append(msgs, strings.TrimSpace(msg.Text))
+		}
+	}
+
+	if len(msgs) == 0 && len(kw.Keywords) == 0 { // message is the text of status since robot 7
+		return strings.TrimSpace(kw.Status.Text)
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// time is like 20210301 10:07:24.123 before robot 7, which uses start time in iso format and elapsed seconds
+func getRobotTime(status model.RobotStatus) (startTime time.Time, endTime time.Time) {
+	if status.StartTime != "" {
+		templ := "20060102 15:04:05.000"
+		startTime, _ = time.ParseInLocation(templ, status.StartTime, time.Local)
+		endTime, _ = time.ParseInLocation(templ, status.EndTime, time.Local)
+		return
+	}
+
+	startTime, _ = time.ParseInLocation("2006-01-02T15:04:05.999999", status.Start, time.Local)
+	elapsed, _ := strconv.ParseFloat(status.Elapsed, 64)
+	endTime = startTime.Add(time.Duration(elapsed * float64(time.Second)))
+	return
+}
+
+// mergeRobotResults merges the results of several output.xml like rebot --merge, such as outputs of pabot processes,
+// if a test is in more than one file, such as a rerun of failed tests, the one ended last is kept.
+func mergeRobotResults(suites []model.UnitTestSuite) []model.UnitTestSuite {
+	merged := model.UnitTestSuite{}
+
+	indexMap := map[string]int{}
+	for _, suite := range suites {
+		for _, cs := range suite.TestCases {
+			key := cs.TestSuite + "." + cs.Title
+
+			if idx, ok := indexMap[key]; ok {
+				if cs.EndTime >= merged.TestCases[idx].EndTime {
+					merged.TestCases[idx] = cs
+				}
+				continue
+			}
+
+			indexMap[key] = len(merged.TestCases)
+			merged.TestCases = append(merged.TestCases, cs)
+		}
+	}
+
+	return []model.UnitTestSuite{merged}
+}
@@ -0,0 +1,202 @@
+package testingService
+
+import (
+	"fmt"
+	"github.com/easysoft/zentaoatf/src/model"
+	constant "github.com/easysoft/zentaoatf/src/utils/const"
+	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
+	"github.com/easysoft/zentaoatf/src/utils/vari"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	tapPlanRegx = regexp.MustCompile(`^1\.\.(\d+)`)
+	tapTestRegx = regexp.MustCompile(`^(ok|not ok)\b\s*(\d*)\s*(.*)$`)
+	// file header printed by prove -v, like "t/login.t .. "
+	tapProveRegx = regexp.MustCompile(`^(\S+) \.{2,}\s*$`)
+	// directive word like SKIP, skipped or SKIP: before the reason
+	tapSkipRegx = regexp.MustCompile(`^(?i)skip\S*\s*`)
+)
+
+// RetrieveTapResult reads tap stream from the result file if provided, or from the output of command
+func RetrieveTapResult(output []string) (suites []model.UnitTestSuite, resultDir string) {
+	resultDir = vari.UnitTestResult
+	if vari.ServerProjectDir != "" {
+		resultDir = vari.ServerProjectDir + resultDir
+	}
+
+	name := constant.UnitTestTypeTap
+	if !fileUtils.IsDir(resultDir) && fileUtils.FileExist(resultDir) {
+		output = strings.Split(fileUtils.ReadFile(resultDir), "\n")
+		name = getTapSuiteName(resultDir)
+	}
+
+	suites = append(suites, ConvertTapResult(output, name))
+	return
+}
+
+// ConvertTapResult converts a tap stream, SKIP and TODO directives are reported as skipped,
+// yaml diagnostics of a test are kept as the failure desc, tests missing from the plan are errors.
+// indented lines of subtests are ignored, the parent test line has the summary of them.
+// name is used as the suite of tests, unless there are file headers printed by prove.
+func ConvertTapResult(lines []string, name string) model.UnitTestSuite {
+	testSuite := model.UnitTestSuite{Name: name}
+
+	suiteName := name
+	plan := 0
+	count := 0
+	last := -1 // the test which yaml diagnostic belongs to
+
+	checkPlan := func() {
+		for numb := count + 1; numb <= plan; numb++ {
+			caseResult := model.UnitResult{TestSuite: suiteName, Title: strconv.Itoa(numb)}
+			caseResult.Error = &model.Failure{Type: "missing",
+				Desc: fmt.Sprintf("test %d is missing, the plan is 1..%d", numb, plan)}
+			testSuite.TestCases = append(testSuite.TestCases, caseResult)
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r\n")
+
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			if strings.TrimSpace(line) == "---" && last >= 0 {
+				block := make([]string, 0)
+				for i++; i < len(lines); i++ {
+					item := strings.TrimRight(lines[i], "\r\n")
+					if strings.TrimSpace(item) == "..." {
+						break
+					}
+					block = append(block, item)
+				}
+				setTapDiagnostic(&testSuite.TestCases[last], block)
+			}
+
+			last = -1
+			continue
+		}
+		last = -1
+
+		if arr := tapTestRegx.FindStringSubmatch(line); arr != nil {
+			if arr[2] == "" && arr[3] == "" { // summary of a file printed by prove
+				continue
+			}
+
+			count++
+			desc, directive := splitTapDirective(arr[3])
+			desc = strings.TrimSpace(strings.TrimPrefix(desc, "-"))
+			if desc == "" {
+				desc = strconv.Itoa(count)
+			}
+			if numb, err := strconv.Atoi(arr[2]); err == nil {
+				count = numb
+			}
+
+			caseResult := model.UnitResult{TestSuite: suiteName, Title: desc}
+
+			upper := strings.ToUpper(directive)
+			if strings.HasPrefix(upper, "SKIP") {
+				caseResult.Skipped = &model.Skipped{Message: strings.TrimSpace(tapSkipRegx.ReplaceAllString(directive, ""))}
+			} else if strings.HasPrefix(upper, "TODO") {
+				if arr[1] == "not ok" { // failure of a todo test is expected
+					caseResult.Skipped = &model.Skipped{Message: directive}
+				}
+			} else if arr[1] == "not ok" {
+				caseResult.Failure = &model.Failure{Type: "fail", Desc: desc}
+			}
+
+			testSuite.TestCases = append(testSuite.TestCases, caseResult)
+			last = len(testSuite.TestCases) - 1
+
+		} else if arr := tapPlanRegx.FindStringSubmatch(line); arr != nil {
+			plan, _ = strconv.Atoi(arr[1])
+
+		} else if strings.HasPrefix(line, "Bail out!") {
+			caseResult := model.UnitResult{TestSuite: suiteName, Title: "Bail out!"}
+			caseResult.Error = &model.Failure{Type: "bail", Desc: strings.TrimSpace(line[len("Bail out!"):])}
+			testSuite.TestCases = append(testSuite.TestCases, caseResult)
+			plan = 0
+
+		} else if arr := tapProveRegx.FindStringSubmatch(line); arr != nil {
+			checkPlan()
+			suiteName = arr[1]
+			plan = 0
+			count = 0
+		}
+	}
+	checkPlan()
+
+	for _, cs := range testSuite.TestCases {
+		testSuite.Time += cs.Duration
+	}
+
+	return testSuite
+}
+
+func getTapSuiteName(file string) string {
+	name := filepath.Base(file)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// the directive starts from the first # not escaped as \#
+func splitTapDirective(str string) (desc string, directive string) {
+	for i := 0; i < len(str); i++ {
+		if str[i] == '#' && (i == 0 || str[i-1] != '\\') {
+			return strings.Replace(str[:i], `\#`, "#", -1), strings.TrimSpace(str[i+1:])
+		}
+	}
+
+	return strings.Replace(str, `\#`, "#", -1), ""
+}
+
+// yaml diagnostic has keys like message, severity and duration_ms, only the top level ones are read
+func setTapDiagnostic(cs *model.UnitResult, block []string) {
+	indent := -1
+	for _, line := range block {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if n := len(line) - len(strings.TrimLeft(line, " \t")); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent < 0 {
+		return
+	}
+
+	message := ""
+	for idx, line := range block {
+		if len(line) >= indent {
+			line = line[indent:]
+		}
+		block[idx] = line
+
+		arr := strings.SplitN(line, ":", 2)
+		if len(arr) < 2 || strings.HasPrefix(line, " ") {
+			continue
+		}
+
+		value := strings.Trim(strings.TrimSpace(arr[1]), `"'`)
+		switch arr[0] {
+		case "message":
+			message = value
+		case "duration_ms":
+			ms, _ := strconv.ParseFloat(value, 64)
+			cs.Duration = float32(ms / 1000)
+		}
+	}
+
+	text := strings.Join(block, "\n")
+	if cs.Failure != nil {
+		cs.Failure.Message = message
+		cs.Failure.Desc = text
+	} else if cs.Error != nil {
+		cs.Error.Message = message
+		cs.Error.Desc = text
+	} else {
+		cs.SystemOut = text
+	}
+}
@@ -0,0 +1,196 @@
+package testingService
+
+import (
+	"fmt"
+	constant "github.com/easysoft/zentaoatf/src/utils/const"
+	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
+	"github.com/easysoft/zentaoatf/src/utils/vari"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// GetUnitTestTool gets the build tool or runner from the first word of command
+func GetUnitTestTool(cmdStr string) string {
+	arr := strings.Fields(cmdStr)
+	if len(arr) == 0 {
+		return ""
+	}
+
+	name := strings.ToLower(filepath.Base(arr[0]))
+	name = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(name, ".exe"), ".bat"), ".cmd")
+
+	switch name {
+	case "mvn", "mvnw":
+		return constant.UnitTestToolMvn
+	case "gradle", "gradlew":
+		return constant.UnitTestToolGradle
+	case "npm", "npx", "yarn", "pnpm":
+		return constant.UnitTestToolNpm
+	case "pytest", "py.test":
+		return constant.UnitTestToolPytest
+	case "python", "python3":
+		if strings.Contains(cmdStr, "-m pytest") {
+			return constant.UnitTestToolPytest
+		} else if strings.Contains(cmdStr, "-m robot") || strings.Contains(cmdStr, "-m pabot") {
+			return constant.UnitTestToolRobot
+		}
+	case "ctest":
+		return constant.UnitTestToolCTest
+	case "robot", "pabot":
+		return constant.UnitTestToolRobot
+	case "dotnet":
+		return constant.UnitTestToolDotnet
+	}
+
+	return ""
+}
+
+// PrepareUnitTest adds the junit xml option to pytest and ctest command if not provided,
+// and gets the result location, old results in built-in location of the tool are removed.
+func PrepareUnitTest(cmdStr string) (cmd string, resultDir string) {
+	cmd = cmdStr
+
+	if vari.UnitTestTool == constant.UnitTestToolPytest && getCmdOption(cmd, "--junitxml", "--junit-xml") == "" {
+		cmd += " --junitxml=" + constant.UnitTestResultPytest
+	} else if vari.UnitTestTool == constant.UnitTestToolCTest && getCmdOption(cmd, "--output-junit") == "" {
+		cmd += " --output-junit " + constant.UnitTestResultCTest
+	}
+
+	resultDir = vari.UnitTestResult
+	needClean := false
+	if resultDir == "" { // not provided by -result
+		resultDir, needClean = getToolResultDir(cmd)
+	}
+	if resultDir == "" {
+		resultDir = "./"
+	}
+
+	if vari.ServerProjectDir != "" {
+		resultDir = vari.ServerProjectDir + resultDir
+	}
+
+	if needClean {
+		cleanUnitResult(resultDir)
+	}
+
+	return
+}
+
+// getToolResultDir gets the result location of tool, needClean is false if it is not written by the tool surely,
+// like results dir of robot wrapper scripts, which should not be removed before run.
+func getToolResultDir(cmdStr string) (resultDir string, needClean bool) {
+	needClean = true
+
+	switch vari.UnitTestTool {
+	case constant.UnitTestToolMvn:
+		if vari.UnitTestType == constant.UnitTestTypeTestNG {
+			resultDir = fmt.Sprintf("target%ssurefire-reports%sjunitreports", constant.PthSep, constant.PthSep)
+		} else {
+			resultDir = fmt.Sprintf("target%ssurefire-reports%s", constant.PthSep, constant.PthSep)
+		}
+
+	case constant.UnitTestToolGradle:
+		resultDir = fmt.Sprintf("build%stest-results%stest%s", constant.PthSep, constant.PthSep, constant.PthSep)
+
+	case constant.UnitTestToolNpm: // jest-junit
+		if file := os.Getenv("JEST_JUNIT_OUTPUT_FILE"); file != "" {
+			return file, true
+		}
+
+		name := os.Getenv("JEST_JUNIT_OUTPUT_NAME")
+		if name == "" {
+			name = "junit.xml"
+		}
+		resultDir = filepath.Join(os.Getenv("JEST_JUNIT_OUTPUT_DIR"), name)
+
+	case constant.UnitTestToolPytest:
+		resultDir = getCmdOption(cmdStr, "--junitxml", "--junit-xml")
+
+	case constant.UnitTestToolCTest: // junit file is relative to test dir
+		resultDir = getCmdOption(cmdStr, "--output-junit")
+		if testDir := getCmdOption(cmdStr, "--test-dir"); testDir != "" && !filepath.IsAbs(resultDir) {
+			resultDir = filepath.Join(testDir, resultDir)
+		}
+
+	case constant.UnitTestToolRobot: // outputs of pabot processes are in pabot_results of the output dir
+		dir := getCmdOption(cmdStr, "--outputdir", "-d")
+		if output := getCmdOption(cmdStr, "--output", "-o"); output != "" {
+			resultDir = filepath.Join(dir, output)
+		} else {
+			resultDir = dir
+		}
+
+	case constant.UnitTestToolDotnet: // trx and logger files are put in TestResults by default
+		resultDir = getCmdOption(cmdStr, "--results-directory")
+		if resultDir == "" {
+			resultDir = constant.UnitTestResultDotnet
+		}
+	}
+
+	if resultDir == "" && (vari.UnitTestType == constant.UnitTestTypeRobot || vari.UnitTestType == constant.UnitTestTypeCypress) {
+		resultDir = vari.UnitTestResults
+		needClean = false
+	}
+
+	return
+}
+
+// get value of option like --junitxml=report.xml or --junitxml report.xml,
+// the name should be a whole field, so that -d won't match the end of prod-d
+func getCmdOption(cmdStr string, names ...string) string {
+	fields := strings.Fields(cmdStr)
+	for _, name := range names {
+		for idx, field := range fields {
+			if field == name && idx+1 < len(fields) {
+				return strings.Trim(fields[idx+1], `"'`)
+			} else if strings.HasPrefix(field, name+"=") {
+				return strings.Trim(field[len(name)+1:], `"'`)
+			}
+		}
+	}
+
+	return ""
+}
+
+// remove the result files of last run, so that they won't be reported again,
+// only the files written by tool are removed, since the dir may be given by user like robot -d .
+func cleanUnitResult(resultDir string) {
+	switch vari.UnitTestTool {
+	case constant.UnitTestToolMvn, constant.UnitTestToolGradle: // reports dir of the tool
+		removeFilesByExt(resultDir, ".xml")
+
+	case constant.UnitTestToolDotnet:
+		removeFilesByExt(resultDir, ".trx")
+
+	case constant.UnitTestToolRobot: // outputs of more pabot processes in last run should be removed too
+		dir := resultDir
+		if path.Ext(resultDir) == ".xml" { // output file
+			dir = filepath.Dir(resultDir)
+			os.Remove(resultDir)
+		} else {
+			os.Remove(filepath.Join(dir, constant.UnitTestResultRobot))
+		}
+		os.RemoveAll(filepath.Join(dir, "pabot_results"))
+
+	default: // junit file of pytest, ctest and jest
+		if !fileUtils.IsDir(resultDir) {
+			os.Remove(resultDir)
+		}
+	}
+}
+
+func removeFilesByExt(dir string, ext string) {
+	if !fileUtils.IsDir(dir) {
+		return
+	}
+
+	fis, _ := ioutil.ReadDir(dir)
+	for _, fi := range fis {
+		if !fi.IsDir() && path.Ext(fi.Name()) == ext {
+			os.Remove(filepath.Join(dir, fi.Name()))
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package testingService
+
+import (
+	"path/filepath"
+	"testing"
+
+	constant "github.com/easysoft/zentaoatf/src/utils/const"
+	"github.com/easysoft/zentaoatf/src/utils/vari"
+)
+
+func TestGetCmdOption(t *testing.T) {
+	cases := []struct {
+		cmd   string
+		names []string
+		value string
+	}{
+		{"pytest tests --junitxml=out/report.xml", []string{"--junitxml"}, "out/report.xml"},
+		{"pytest tests --junit-xml 'report.xml'", []string{"--junitxml", "--junit-xml"}, "report.xml"},
+		{"robot --variable env:prod-d x tests", []string{"--outputdir", "-d"}, ""},
+		{"robot --name demo-o x tests", []string{"--output", "-o"}, ""},
+		{"robot -d reports tests", []string{"--outputdir", "-d"}, "reports"},
+		{"robot --outputdir=reports tests", []string{"--outputdir", "-d"}, "reports"},
+		{"robot --outputdir reports tests", []string{"--output", "-o"}, ""},
+		{"ctest --output-junit", []string{"--output-junit"}, ""},
+	}
+
+	for _, c := range cases {
+		if value := getCmdOption(c.cmd, c.names...); value != c.value {
+			t.Errorf("getCmdOption(%q, %v) = %q, want %q", c.cmd, c.names, value, c.value)
+		}
+	}
+}
+
+func TestGetToolResultDirOfRobot(t *testing.T) {
+	vari.UnitTestType = constant.UnitTestTypeRobot
+	defer func() { vari.UnitTestType, vari.UnitTestTool = "", "" }()
+
+	cases := []struct {
+		cmd       string
+		dir       string
+		needClean bool
+	}{
+		{"./run.sh", vari.UnitTestResults, false},
+		{"robot tests", vari.UnitTestResults, false},
+		{"robot -d reports tests", "reports", true},
+		{"pabot -d reports -o out.xml tests", filepath.Join("reports", "out.xml"), true},
+		{"python -m robot --output out.xml tests", "out.xml", true},
+	}
+
+	for _, c := range cases {
+		vari.UnitTestTool = GetUnitTestTool(c.cmd)
+		if dir, needClean := getToolResultDir(c.cmd); dir != c.dir || needClean != c.needClean {
+			t.Errorf("getToolResultDir(%q) = %q, %v, want %q, %v", c.cmd, dir, needClean, c.dir, c.needClean)
+		}
+	}
+}
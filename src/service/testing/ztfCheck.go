@@ -16,8 +16,9 @@ import (
 	"strings"
 )
 
-func CheckCaseResult(file string, logs string, report *model.TestReport, idx int, total int, secs string, pathMaxWidth int, numbMaxWidth int) {
-	_, _, expectMap, isOldFormat := scriptUtils.GetStepAndExpectMap(file)
+// getCaseExpectMap reads expects of steps, which are replaced by the ones in independent .exp file if there is
+func getCaseExpectMap(file string) (expectMap maps.Map, isOldFormat bool) {
+	_, _, expectMap, isOldFormat = scriptUtils.GetStepAndExpectMap(file)
 
 	isIndependent, expectIndependentContent := zentaoUtils.GetDependentExpect(file)
 	if isIndependent {
@@ -28,29 +29,44 @@ func CheckCaseResult(file string, logs string, report *model.TestReport, idx int
 		}
 	}
 
+	return
+}
+
+// CheckCaseResult checks logs of a run with expects, header and expects are read before running, not for each data row
+func CheckCaseResult(file string, header zentaoUtils.CaseHeader, expectMap maps.Map, isOldFormat bool,
+	logs string, isTimeout bool) model.FuncResult {
+
 	skip := false
 	actualArr := make([][]string, 0)
+	actualLabels := make([]string, 0)
 	if isOldFormat {
 		skip, actualArr = zentaoUtils.ReadLogArrObsolete(logs)
 	} else {
-		skip, actualArr = zentaoUtils.ReadLogArr(logs)
+		skip, actualArr, actualLabels = zentaoUtils.ReadLogArr(logs)
 	}
 
 	language := langUtils.GetLangByFile(file)
-	ValidateCaseResult(file, language, expectMap, skip, actualArr, report,
-		idx, total, secs, pathMaxWidth, numbMaxWidth)
+	return ValidateCaseResult(file, header, language, expectMap, skip, isTimeout, actualArr, actualLabels)
 }
 
-func ValidateCaseResult(scriptFile string, langType string,
-	expectMap maps.Map, skip bool, actualArr [][]string, report *model.TestReport,
-	idx int, total int, secs string, pathMaxWidth int, numbMaxWidth int) {
-
-	_, caseId, productId, title := zentaoUtils.GetCaseInfo(scriptFile)
+func ValidateCaseResult(scriptFile string, header zentaoUtils.CaseHeader, langType string,
+	expectMap maps.Map, skip bool, isTimeout bool, actualArr [][]string, actualLabels []string) model.FuncResult {
 
 	stepLogs := make([]model.StepLog, 0)
 	caseResult := constant.PASS.String()
 	noExpects := true
 
+	// actual with a label is bound to the step with the same number, others by position
+	labelToActual := map[string][]string{}
+	positionalArr := make([][]string, 0)
+	for index, actual := range actualArr {
+		if index < len(actualLabels) && actualLabels[index] != "" {
+			labelToActual[actualLabels[index]] = actual
+		} else {
+			positionalArr = append(positionalArr, actual)
+		}
+	}
+
 	if skip {
 		caseResult = constant.SKIP.String()
 	} else {
@@ -70,8 +86,13 @@ func ValidateCaseResult(scriptFile string, langType string,
 
 			expectLines := strings.Split(expect, "\n")
 			var actualLines []string
-			if len(actualArr) > idx {
-				actualLines = actualArr[idx]
+			if lines, ok := labelToActual[strings.TrimRight(numb, ".")]; ok {
+				actualLines = lines
+			} else {
+				if len(positionalArr) > idx {
+					actualLines = positionalArr[idx]
+				}
+				idx++
 			}
 
 			stepResult, checkpointLogs := ValidateStepResult(langType, expectLines, actualLines)
@@ -80,26 +101,66 @@ func ValidateCaseResult(scriptFile string, langType string,
 			if !stepResult {
 				caseResult = constant.FAIL.String()
 			}
-
-			idx++
 		}
 	}
 
 	if noExpects {
 		caseResult = constant.SKIP.String()
 	}
+	if isTimeout {
+		caseResult = constant.TIMEOUT.String()
+	}
 
-	if caseResult == constant.FAIL.String() {
-		report.Fail = report.Fail + 1
-	} else if caseResult == constant.PASS.String() {
-		report.Pass = report.Pass + 1
-	} else if caseResult == constant.SKIP.String() {
-		report.Skip = report.Skip + 1
+	cs := model.FuncResult{Id: header.CaseId, ProductId: header.ProductId, Title: header.Title,
+		Tags: header.Tags, Priority: header.Priority, Owner: header.Owner,
+		Path: scriptFile, Status: caseResult, Steps: stepLogs}
+
+	return cs
+}
+
+// case fails if any data row fails, and its steps are in sub results
+func mergeDataRowResult(subResults []model.FuncResult) model.FuncResult {
+	cs := subResults[0]
+	cs.Row = nil
+	cs.Steps = make([]model.StepLog, 0)
+
+	statuses := make([]string, 0)
+	for idx := range subResults {
+		subResults[idx].Title += " " + i118Utils.I118Prt.Sprintf("data_row", idx+1)
+		statuses = append(statuses, subResults[idx].Status)
 	}
-	report.Total = report.Total + 1
+	cs.SubResults = subResults
+
+	cs.Status = constant.SKIP.String()
+	for _, status := range []string{constant.FAIL.String(), constant.TIMEOUT.String(), constant.PASS.String()} {
+		if stringUtils.FindInArr(status, statuses) {
+			cs.Status = status
+			break
+		}
+	}
+
+	return cs
+}
+
+// mark case as blocked without running it, e.g. its setup hook failed
+func BlockCase(scriptFile string, report *model.TestReport, total int, pathMaxWidth int, numbMaxWidth int) {
+	_, caseId, productId, title := zentaoUtils.GetCaseInfo(scriptFile)
+	tags, priority, owner := zentaoUtils.GetCaseMeta(scriptFile)
 
 	cs := model.FuncResult{Id: caseId, ProductId: productId, Title: title,
-		Path: scriptFile, Status: caseResult, Steps: stepLogs}
+		Tags: tags, Priority: priority, Owner: owner,
+		Path: scriptFile, Status: constant.BLOCKED.String(), Steps: make([]model.StepLog, 0)}
+
+	appendCaseResult(cs, report, total, "0.00", pathMaxWidth, numbMaxWidth)
+}
+
+func appendCaseResult(cs model.FuncResult, report *model.TestReport, total int, secs string, pathMaxWidth int, numbMaxWidth int) {
+	reportLock.Lock()
+	defer reportLock.Unlock()
+
+	countCaseResult(report, cs.Status, 1)
+	report.Total = report.Total + 1
+
 	report.FuncResult = append(report.FuncResult, cs)
 
 	// print case result to console
@@ -115,9 +176,22 @@ func ValidateCaseResult(scriptFile string, langType string,
 		path += postFix
 	}
 
+	// numbered by finish order, the same as idx+1 if not run in parallel
+	seq := report.Total
+
 	format := "(%" + width + "d/%d) %s [%s] [%" + numbWidth + "d. %s] (%ss)"
-	logUtils.Screen(fmt.Sprintf(format, idx+1, total, statusColor, path, cs.Id, cs.Title, secs))
-	logUtils.Result(fmt.Sprintf(format, idx+1, total, i118Utils.I118Prt.Sprintf(cs.Status), path, cs.Id, cs.Title, secs))
+	logUtils.Screen(fmt.Sprintf(format, seq, total, statusColor, path, cs.Id, cs.Title, secs))
+	logUtils.Result(fmt.Sprintf(format, seq, total, i118Utils.I118Prt.Sprintf(cs.Status), path, cs.Id, cs.Title, secs))
+}
+
+func countCaseResult(report *model.TestReport, caseResult string, delta int) {
+	if caseResult == constant.FAIL.String() || caseResult == constant.TIMEOUT.String() {
+		report.Fail = report.Fail + delta
+	} else if caseResult == constant.PASS.String() {
+		report.Pass = report.Pass + delta
+	} else if caseResult == constant.SKIP.String() || caseResult == constant.BLOCKED.String() {
+		report.Skip = report.Skip + delta
+	}
 }
 
 func ValidateStepResult(langType string, expectLines []string, actualLines []string) (bool, []model.CheckPointLog) {
@@ -133,19 +207,16 @@ func ValidateStepResult(langType string, expectLines []string, actualLines []str
 		}
 
 		expect = strings.TrimSpace(expect)
-		var pass bool
+		pass, actual := stringUtils.AssertExpect(expect, log, langType)
 		if expect[:1] == "`" && expect[len(expect)-1:] == "`" {
 			expect = expect[1 : len(expect)-1]
-			pass = stringUtils.MatchString(expect, log, langType)
-		} else {
-			pass = strings.Contains(log, expect)
 		}
 
 		if !pass {
 			stepResult = false
 		}
 
-		cp := model.CheckPointLog{Numb: indx2 + 1, Status: pass, Expect: expect, Actual: log}
+		cp := model.CheckPointLog{Numb: indx2 + 1, Status: pass, Expect: expect, Actual: actual}
 		checkpointLogs = append(checkpointLogs, cp)
 
 		indx2++
@@ -3,15 +3,23 @@ package testingService
 import (
 	"fmt"
 	"github.com/easysoft/zentaoatf/src/model"
+	constant "github.com/easysoft/zentaoatf/src/utils/const"
 	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
 	"github.com/easysoft/zentaoatf/src/utils/log"
 	"github.com/easysoft/zentaoatf/src/utils/shell"
+	"github.com/easysoft/zentaoatf/src/utils/vari"
+	zentaoUtils "github.com/easysoft/zentaoatf/src/utils/zentao"
 	"github.com/fatih/color"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+var reportLock sync.Mutex
+
 func ExeScripts(casesToRun []string, casesToIgnore []string, report *model.TestReport, pathMaxWidth int, numbMaxWidth int) {
 	now := time.Now()
 	startTime := now.Unix()
@@ -34,8 +42,42 @@ func ExeScripts(casesToRun []string, casesToIgnore []string, report *model.TestR
 			i118Utils.I118Prt.Sprintf("ignore_scripts", color.CyanString(strconv.Itoa(len(casesToIgnore)))) + postFix)
 	}
 
-	for idx, file := range casesToRun {
-		ExeScript(file, report, idx, len(casesToRun), pathMaxWidth, numbMaxWidth)
+	pass := true
+	if vari.Before != "" {
+		var logs string
+		pass, logs = exeHook("before", vari.Before, vari.ServerWorkDir)
+		logUtils.Log(logs)
+	}
+
+	if pass {
+		exeScriptsByDir(casesToRun, report, pathMaxWidth, numbMaxWidth)
+	} else {
+		for _, file := range casesToRun {
+			BlockCase(file, report, len(casesToRun), pathMaxWidth, numbMaxWidth)
+		}
+	}
+	sortFuncResult(report, casesToRun)
+
+	for attempt := 1; attempt <= vari.Retry; attempt++ {
+		casesToRetry := getFailedScripts(*report)
+		if len(casesToRetry) == 0 {
+			break
+		}
+
+		logUtils.Result("\n" + logUtils.GetWholeLine(time.Now().Format("2006-01-02 15:04:05")+" "+
+			i118Utils.I118Prt.Sprintf("retry_failed_scripts", strconv.Itoa(len(casesToRetry)), attempt, vari.Retry)+":", "="))
+		logUtils.Screen("\n" + logUtils.GetWholeLine(time.Now().Format("2006-01-02 15:04:05")+" "+
+			i118Utils.I118Prt.Sprintf("retry_failed_scripts", color.CyanString(strconv.Itoa(len(casesToRetry))), attempt, vari.Retry)+":", "="))
+
+		retryReport := model.TestReport{FuncResult: make([]model.FuncResult, 0)}
+		exeScriptsByDir(casesToRetry, &retryReport, pathMaxWidth, numbMaxWidth)
+
+		mergeRetryResult(report, retryReport)
+	}
+
+	if pass && vari.After != "" {
+		_, logs := exeHook("after", vari.After, vari.ServerWorkDir)
+		logUtils.Log(logs)
 	}
 
 	endTime := time.Now().Unix()
@@ -43,30 +85,234 @@ func ExeScripts(casesToRun []string, casesToIgnore []string, report *model.TestR
 	report.Duration = endTime - startTime
 }
 
+// run scripts in dirs with _setup or _teardown hooks dir by dir wrapped by the hooks,
+// others are run together, so that they're in one pool if run in parallel.
+func exeScriptsByDir(casesToRun []string, report *model.TestReport, pathMaxWidth int, numbMaxWidth int) {
+	dirs := make([]string, 0)
+	dirToCases := map[string][]string{}
+	casesWithoutHook := make([]string, 0)
+	for _, file := range casesToRun {
+		dir := filepath.Dir(file)
+		if getHookFile(dir, constant.HookSetupFile) == "" && getHookFile(dir, constant.HookTeardownFile) == "" {
+			casesWithoutHook = append(casesWithoutHook, file)
+			continue
+		}
+
+		if _, ok := dirToCases[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		dirToCases[dir] = append(dirToCases[dir], file)
+	}
+
+	exeScriptList(casesWithoutHook, 0, len(casesToRun), report, pathMaxWidth, numbMaxWidth)
+
+	offset := len(casesWithoutHook)
+	for _, dir := range dirs {
+		cases := dirToCases[dir]
+
+		pass := true
+		if setup := getHookFile(dir, constant.HookSetupFile); setup != "" {
+			var logs string
+			pass, logs = exeHook("setup", getHookFileCmd(setup), dir)
+			logUtils.Log(logs)
+		}
+
+		if pass {
+			exeScriptList(cases, offset, len(casesToRun), report, pathMaxWidth, numbMaxWidth)
+
+			if teardown := getHookFile(dir, constant.HookTeardownFile); teardown != "" {
+				_, logs := exeHook("teardown", getHookFileCmd(teardown), dir)
+				logUtils.Log(logs)
+			}
+		} else {
+			for _, file := range cases {
+				BlockCase(file, report, len(casesToRun), pathMaxWidth, numbMaxWidth)
+			}
+		}
+
+		offset += len(cases)
+	}
+}
+
+func exeScriptList(casesToRun []string, offset int, total int, report *model.TestReport, pathMaxWidth int, numbMaxWidth int) {
+	if vari.Parallel > 1 && len(casesToRun) > 1 {
+		exeScriptsInParallel(casesToRun, offset, total, report, pathMaxWidth, numbMaxWidth)
+	} else {
+		for idx, file := range casesToRun {
+			ExeScript(file, report, offset+idx, total, pathMaxWidth, numbMaxWidth)
+		}
+	}
+}
+
+func exeScriptsInParallel(casesToRun []string, offset int, total int, report *model.TestReport, pathMaxWidth int, numbMaxWidth int) {
+	workers := vari.Parallel
+	if workers > len(casesToRun) {
+		workers = len(casesToRun)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				ExeScript(casesToRun[idx], report, offset+idx, total, pathMaxWidth, numbMaxWidth)
+			}
+		}()
+	}
+
+	for idx := range casesToRun {
+		indexes <- idx
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// keep results in the same order as scripts were found
+func sortFuncResult(report *model.TestReport, casesToRun []string) {
+	order := map[string]int{}
+	for idx, file := range casesToRun {
+		order[file] = idx
+	}
+	sort.SliceStable(report.FuncResult, func(i, j int) bool {
+		return order[report.FuncResult[i].Path] < order[report.FuncResult[j].Path]
+	})
+}
+
 func ExeScript(file string, report *model.TestReport, idx int, total int, pathMaxWidth int, numbMaxWidth int) {
 	startTime := time.Now()
 
-	logUtils.Log("===start " + file + " at " + startTime.Format("2006-01-02 15:04:05"))
-	logs := ""
+	// collect lines of a case and write them at once, so that cases run in parallel not interleave in log
+	logLines := make([]string, 0)
+	logLines = append(logLines, "===start "+file+" at "+startTime.Format("2006-01-02 15:04:05"))
+
+	header := zentaoUtils.ReadCaseHeader(file)
+	if header.Setup != "" {
+		pass, hookLogs := exeHook("setup", header.Setup, filepath.Dir(file))
+		logLines = append(logLines, hookLogs)
 
-	out, err := shellUtils.ExecScriptFile(file)
+		if !pass {
+			logLines = append(logLines, "===end "+file+" at "+time.Now().Format("2006-01-02 15:04:05"))
+			logUtils.Log(strings.Join(logLines, "\n"))
+
+			BlockCase(file, report, total, pathMaxWidth, numbMaxWidth)
+			return
+		}
+	}
+
+	timeout := vari.Timeout
+	if header.Timeout > 0 {
+		timeout = header.Timeout
+	}
+
+	var cs model.FuncResult
+	expectMap, isOldFormat := getCaseExpectMap(file)
+	if len(header.DataRows) == 0 {
+		logs, isTimeout := exeScriptOnce(file, timeout, nil, &logLines)
+		cs = CheckCaseResult(file, header, expectMap, isOldFormat, logs, isTimeout)
+	} else { // run once for each data row
+		subResults := make([]model.FuncResult, 0)
+		for rowIdx, row := range header.DataRows {
+			env := getDataRowEnv(row, rowIdx+1)
+			logLines = append(logLines, "===row "+strconv.Itoa(rowIdx+1)+" "+strings.Join(env, " "))
+
+			rowStartTime := time.Now()
+			logs, isTimeout := exeScriptOnce(file, timeout, env, &logLines)
+			sub := CheckCaseResult(file, header, expectMap, isOldFormat, logs, isTimeout)
+			sub.Row = row
+			sub.Duration = float32(time.Now().Sub(rowStartTime).Seconds())
+
+			subResults = append(subResults, sub)
+		}
+		cs = mergeDataRowResult(subResults)
+	}
+
+	if header.Teardown != "" {
+		_, hookLogs := exeHook("teardown", header.Teardown, filepath.Dir(file))
+		logLines = append(logLines, hookLogs)
+	}
+
+	entTime := time.Now()
+	secs := fmt.Sprintf("%.2f", float32(entTime.Sub(startTime)/time.Second))
+	cs.Duration = float32(entTime.Sub(startTime).Seconds())
+
+	logLines = append(logLines, "===end "+file+" at "+entTime.Format("2006-01-02 15:04:05"))
+	if idx < total-1 {
+		logLines = append(logLines, "")
+	}
+	logUtils.Log(strings.Join(logLines, "\n"))
+
+	appendCaseResult(cs, report, total, secs, pathMaxWidth, numbMaxWidth)
+}
+
+func exeScriptOnce(file string, timeout int, env []string, logLines *[]string) (logs string, isTimeout bool) {
+	out, err, isTimeout := shellUtils.ExecScriptFileWithTimeout(file, timeout, env)
 	out = strings.Trim(out, "\n")
 
 	if out != "" {
-		logUtils.Log(out)
+		*logLines = append(*logLines, out)
 		logs = out
 	}
 	if err != "" {
 		logUtils.Error(err)
 	}
+	if isTimeout {
+		msg := i118Utils.I118Prt.Sprintf("script_timeout", file, timeout)
+		*logLines = append(*logLines, msg)
+		logUtils.Error(msg)
+	}
 
-	entTime := time.Now()
-	secs := fmt.Sprintf("%.2f", float32(entTime.Sub(startTime)/time.Second))
+	return
+}
 
-	logUtils.Log("===end " + file + " at " + entTime.Format("2006-01-02 15:04:05"))
-	CheckCaseResult(file, logs, report, idx, total, secs, pathMaxWidth, numbMaxWidth)
+// columns of the row are passed to script as environment variables, with ZTF_DATA_ROW for the row number
+func getDataRowEnv(row map[string]string, numb int) (env []string) {
+	names := make([]string, 0)
+	for name := range row {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 
-	if idx < total-1 {
-		logUtils.Log("")
+	for _, name := range names {
+		env = append(env, name+"="+row[name])
+	}
+	env = append(env, "ZTF_DATA_ROW="+strconv.Itoa(numb))
+
+	return
+}
+
+func getFailedScripts(report model.TestReport) (files []string) {
+	for _, cs := range report.FuncResult {
+		if cs.Status == constant.FAIL.String() || cs.Status == constant.TIMEOUT.String() {
+			files = append(files, cs.Path)
+		}
+	}
+
+	return
+}
+
+// replace failed results with retried ones, and keep every attempt in the case result
+func mergeRetryResult(report *model.TestReport, retryReport model.TestReport) {
+	for _, retried := range retryReport.FuncResult {
+		for idx := range report.FuncResult {
+			cs := &report.FuncResult[idx]
+			if cs.Path != retried.Path {
+				continue
+			}
+
+			attempts := cs.Attempts
+			if len(attempts) == 0 {
+				attempts = append(attempts, model.FuncAttempt{Status: cs.Status, Steps: cs.Steps})
+			}
+			attempts = append(attempts, model.FuncAttempt{Status: retried.Status, Steps: retried.Steps})
+
+			countCaseResult(report, cs.Status, -1)
+			countCaseResult(report, retried.Status, 1)
+
+			*cs = retried
+			cs.Attempts = attempts
+			cs.Flaky = retried.Status == constant.PASS.String()
+		}
 	}
 }
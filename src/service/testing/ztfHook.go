@@ -0,0 +1,65 @@
+package testingService
+
+import (
+	commonUtils "github.com/easysoft/zentaoatf/src/utils/common"
+	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
+	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
+	logUtils "github.com/easysoft/zentaoatf/src/utils/log"
+	shellUtils "github.com/easysoft/zentaoatf/src/utils/shell"
+	"github.com/easysoft/zentaoatf/src/utils/vari"
+	"github.com/fatih/color"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// run a setup or teardown hook, return false if it failed, and the logs to be written next to case logs
+func exeHook(name string, cmdStr string, dir string) (pass bool, logs string) {
+	startTime := time.Now()
+
+	logLines := make([]string, 0)
+	logLines = append(logLines, "===hook "+name+" "+cmdStr+" at "+startTime.Format("2006-01-02 15:04:05"))
+
+	out, err := shellUtils.ExeHook(cmdStr, dir, vari.Timeout)
+	out = strings.Trim(out, "\n")
+	if out != "" {
+		logLines = append(logLines, out)
+	}
+
+	pass = err == nil
+	if !pass {
+		msg := i118Utils.I118Prt.Sprintf("hook_fail", name, cmdStr, err.Error())
+		logLines = append(logLines, msg)
+
+		logUtils.Error(msg)
+		logUtils.Screen(color.RedString(msg))
+	}
+
+	logLines = append(logLines, "===hook end "+name+" at "+time.Now().Format("2006-01-02 15:04:05"))
+	logs = strings.Join(logLines, "\n")
+
+	return
+}
+
+// _setup.sh or _teardown.sh in script dir, .bat for windows
+func getHookFile(dir string, name string) string {
+	ext := ".sh"
+	if commonUtils.IsWin() {
+		ext = ".bat"
+	}
+
+	file := filepath.Join(dir, name+ext)
+	if !fileUtils.FileExist(file) {
+		return ""
+	}
+
+	return file
+}
+
+func getHookFileCmd(file string) string {
+	if commonUtils.IsWin() {
+		return "\"" + file + "\""
+	}
+
+	return "/bin/bash \"" + file + "\""
+}
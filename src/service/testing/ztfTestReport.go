@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"github.com/easysoft/zentaoatf/src/model"
 	commonUtils "github.com/easysoft/zentaoatf/src/utils/common"
+	constant "github.com/easysoft/zentaoatf/src/utils/const"
 	"github.com/easysoft/zentaoatf/src/utils/file"
 	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
 	"github.com/easysoft/zentaoatf/src/utils/log"
 	"github.com/easysoft/zentaoatf/src/utils/vari"
 	"github.com/fatih/color"
 	"github.com/mattn/go-runewidth"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -26,54 +28,52 @@ func GenZTFTestReport(report model.TestReport, pathMaxWidth int) {
 	failedCaseLines := make([]string, 0)
 	failedCaseLinesWithCheckpoint := make([]string, 0)
 
-	for _, cs := range report.FuncResult {
-		if cs.Status == "fail" {
-			if failedCount > 0 {
-				failedCaseLinesWithCheckpoint = append(failedCaseLinesWithCheckpoint, "")
-			}
-			failedCount++
+	for _, cs := range getFailedResults(report.FuncResult) {
+		if failedCount > 0 {
+			failedCaseLinesWithCheckpoint = append(failedCaseLinesWithCheckpoint, "")
+		}
+		failedCount++
 
-			path := cs.Path
-			lent := runewidth.StringWidth(path)
+		path := cs.Path
+		lent := runewidth.StringWidth(path)
 
-			if pathMaxWidth > lent {
-				postFix := strings.Repeat(" ", pathMaxWidth-lent)
-				path += postFix
-			}
+		if pathMaxWidth > lent {
+			postFix := strings.Repeat(" ", pathMaxWidth-lent)
+			path += postFix
+		}
 
-			line := fmt.Sprintf("[%s] %d.%s", cs.Path, cs.Id, cs.Title)
-			failedCaseLines = append(failedCaseLines, line)
-			failedCaseLinesWithCheckpoint = append(failedCaseLinesWithCheckpoint, line)
+		line := fmt.Sprintf("[%s] %d.%s", cs.Path, cs.Id, cs.Title)
+		failedCaseLines = append(failedCaseLines, line)
+		failedCaseLinesWithCheckpoint = append(failedCaseLinesWithCheckpoint, line)
 
-			if len(cs.Steps) > 0 {
-				stepNumb := 0
-				for _, step := range cs.Steps {
-					if step.Status {
-						continue
-					}
+		if len(cs.Steps) > 0 {
+			stepNumb := 0
+			for _, step := range cs.Steps {
+				if step.Status {
+					continue
+				}
 
-					if stepNumb > 0 {
-						failedCaseLinesWithCheckpoint = append(failedCaseLinesWithCheckpoint, "")
-					}
-					stepNumb++
+				if stepNumb > 0 {
+					failedCaseLinesWithCheckpoint = append(failedCaseLinesWithCheckpoint, "")
+				}
+				stepNumb++
 
-					step.Id = strings.TrimRight(step.Id, ".")
-					status := i118Utils.I118Prt.Sprintf(commonUtils.BoolToPass(step.Status))
-					failedCaseLinesWithCheckpoint = append(failedCaseLinesWithCheckpoint, fmt.Sprintf("Step %s: %s", step.Id, status))
+				step.Id = strings.TrimRight(step.Id, ".")
+				status := i118Utils.I118Prt.Sprintf(commonUtils.BoolToPass(step.Status))
+				failedCaseLinesWithCheckpoint = append(failedCaseLinesWithCheckpoint, fmt.Sprintf("Step %s: %s", step.Id, status))
 
-					for idx1, cp := range step.CheckPoints {
-						//cpStatus := commonUtils.BoolToPass(step.Status)
-						failedCaseLinesWithCheckpoint = append(failedCaseLinesWithCheckpoint, fmt.Sprintf("[Expect] %s", cp.Expect))
-						failedCaseLinesWithCheckpoint = append(failedCaseLinesWithCheckpoint, fmt.Sprintf("[Actual] %s", cp.Actual))
+				for idx1, cp := range step.CheckPoints {
+					//cpStatus := commonUtils.BoolToPass(step.Status)
+					failedCaseLinesWithCheckpoint = append(failedCaseLinesWithCheckpoint, fmt.Sprintf("[Expect] %s", cp.Expect))
+					failedCaseLinesWithCheckpoint = append(failedCaseLinesWithCheckpoint, fmt.Sprintf("[Actual] %s", cp.Actual))
 
-						if idx1 < len(step.CheckPoints)-1 {
-							failedCaseLinesWithCheckpoint = append(failedCaseLinesWithCheckpoint, "")
-						}
+					if idx1 < len(step.CheckPoints)-1 {
+						failedCaseLinesWithCheckpoint = append(failedCaseLinesWithCheckpoint, "")
 					}
 				}
-			} else {
-				failedCaseLinesWithCheckpoint = append(failedCaseLinesWithCheckpoint, "   "+i118Utils.I118Prt.Sprintf("no_checkpoints"))
 			}
+		} else {
+			failedCaseLinesWithCheckpoint = append(failedCaseLinesWithCheckpoint, "   "+i118Utils.I118Prt.Sprintf("no_checkpoints"))
 		}
 	}
 	if failedCount > 0 {
@@ -82,6 +82,20 @@ func GenZTFTestReport(report model.TestReport, pathMaxWidth int) {
 		logUtils.Result(strings.Join(failedCaseLinesWithCheckpoint, "\n"))
 	}
 
+	// print flaky case, which passed after retry
+	flakyCaseLines := make([]string, 0)
+	for _, cs := range report.FuncResult {
+		if cs.Flaky {
+			flakyCaseLines = append(flakyCaseLines,
+				fmt.Sprintf("[%s] %d.%s (%s)", cs.Path, cs.Id, cs.Title,
+					i118Utils.I118Prt.Sprintf("attempts", len(cs.Attempts))))
+		}
+	}
+	if len(flakyCaseLines) > 0 {
+		logUtils.ScreenAndResult("\n" + i118Utils.I118Prt.Sprintf("flaky_scripts"))
+		logUtils.ScreenAndResult(strings.Join(flakyCaseLines, "\n"))
+	}
+
 	secTag := ""
 	if vari.Config.Language == "en" && report.Duration > 1 {
 		secTag = "s"
@@ -112,4 +126,29 @@ func GenZTFTestReport(report model.TestReport, pathMaxWidth int) {
 	report.ProductId, _ = strconv.Atoi(vari.ProductId)
 	json, _ := json.Marshal(report)
 	fileUtils.WriteFile(vari.LogDir+"result.json", string(json))
+	SaveToHistory(report)
+
+	if vari.JUnitReport != "" {
+		GenJUnitReport(report, vari.JUnitReport)
+	}
+	if vari.OutputFormat == constant.OutputFormatTap {
+		fmt.Fprint(os.Stdout, GenTapReport(report))
+	}
+}
+
+// failed rows instead of the case are listed for a data-driven case
+func getFailedResults(results []model.FuncResult) (ret []model.FuncResult) {
+	for _, cs := range results {
+		if cs.Status != constant.FAIL.String() && cs.Status != constant.TIMEOUT.String() {
+			continue
+		}
+
+		if len(cs.SubResults) > 0 {
+			ret = append(ret, getFailedResults(cs.SubResults)...)
+		} else {
+			ret = append(ret, cs)
+		}
+	}
+
+	return
 }
@@ -7,17 +7,40 @@ import (
 	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
 	logUtils "github.com/easysoft/zentaoatf/src/utils/log"
 	"github.com/easysoft/zentaoatf/src/utils/vari"
-	"github.com/fatih/color"
+	zentaoUtils "github.com/easysoft/zentaoatf/src/utils/zentao"
+	"net/http"
 )
 
-func Login(baseUrl string, account string, password string) bool {
+// Login returns the error of getting config or login, use client.GetErrorMsg to tell users what's wrong
+func Login(baseUrl string, account string, password string) (err error) {
 
-	ok := GetConfig(baseUrl)
+	err = GetConfig(baseUrl)
 
-	if !ok {
-		return false
+	if err != nil {
+		return
 	}
 
+	if zentaoUtils.UseRestApi() {
+		err = loginRest(baseUrl, account, password)
+	} else {
+		err = loginSession(baseUrl, account, password)
+	}
+
+	// rejected like {"status": "failed", "reason": "..."}, or 400 of restful api
+	if zentaoErr, ok := err.(*client.ZentaoError); ok && zentaoErr.StatusCode >= http.StatusOK &&
+		zentaoErr.StatusCode < http.StatusInternalServerError && zentaoErr.StatusCode != http.StatusNotFound {
+		zentaoErr.Login = true
+	}
+
+	if err == nil && vari.Verbose {
+		logUtils.Screen(i118Utils.I118Prt.Sprintf("success_to_login"))
+	}
+	return
+}
+
+// loginSession logs in with the session of json views,
+// which is also used by restful api mode for the data not provided by api.
+func loginSession(baseUrl string, account string, password string) error {
 	// $referer = '', $from = ''
 	uri := ""
 	if vari.RequestType == constant.RequestTypePathInfo {
@@ -31,19 +54,8 @@ func Login(baseUrl string, account string, password string) bool {
 	params["account"] = account
 	params["password"] = password
 
-	var log string
-	_, ok = client.PostStr(url, params)
-	if ok {
-		if vari.Verbose {
-			log = i118Utils.I118Prt.Sprintf("success_to_login")
-			logUtils.PrintToCmd("success_to_login", color.FgRed)
-			logUtils.Screen(log)
-		}
-	} else {
-		log = i118Utils.I118Prt.Sprintf("fail_to_login")
-		logUtils.PrintToCmd(log, color.FgRed)
-	}
-	return ok
+	_, err := client.PostStr(url, params)
+	return err
 }
 
 // func GetCookie(baseUrl string, account string, password string) bool {
@@ -84,25 +96,25 @@ func Login(baseUrl string, account string, password string) bool {
 // 	return ok
 // }
 
-func GetConfig(baseUrl string) bool {
+func GetConfig(baseUrl string) error {
 	if vari.RequestType != "" {
-		return true
+		return nil
 	}
 
 	url := baseUrl + "?mode=getconfig"
 
-	body, ok := client.Get(url)
+	body, err := client.Get(url)
+	if err != nil {
+		return err
+	}
 
-	if ok {
-		json, _ := simplejson.NewJson([]byte(body))
+	json, _ := simplejson.NewJson([]byte(body))
 
-		vari.SessionId, _ = json.Get("sessionID").String()
-		vari.SessionVar, _ = json.Get("sessionVar").String()
-		vari.RequestType, _ = json.Get("requestType").String()
-		vari.RequestFix, _ = json.Get("requestFix").String()
+	vari.SessionId, _ = json.Get("sessionID").String()
+	vari.SessionVar, _ = json.Get("sessionVar").String()
+	vari.RequestType, _ = json.Get("requestType").String()
+	vari.RequestFix, _ = json.Get("requestFix").String()
+	vari.ZentaoVersion, _ = json.Get("version").String()
 
-		return true
-	} else {
-		return false
-	}
+	return nil
 }
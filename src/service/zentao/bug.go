@@ -29,6 +29,14 @@ func PrepareBug(resultDir string, caseIdStr string) (model.Bug, string) {
 			continue
 		}
 
+		// report bug with the first failed row of a data-driven case
+		for _, sub := range cs.SubResults {
+			if sub.Status != constant.PASS.String() {
+				cs = sub
+				break
+			}
+		}
+
 		product := cs.ProductId
 		GetBugFiledOptions(product)
 
@@ -65,19 +73,61 @@ func PrepareBug(resultDir string, caseIdStr string) (model.Bug, string) {
 		return bug, stepIds
 	}
 
+	for _, cs := range report.UnitResult {
+		if cs.Id == caseId {
+			return prepareUnitBug(report, cs), ""
+		}
+	}
+
 	return model.Bug{}, ""
 }
 
+// bug of a failed unit test, with its failure, output and attachments
+func prepareUnitBug(report model.TestReport, cs model.UnitResult) model.Bug {
+	GetBugFiledOptions(report.ProductId)
+
+	steps := make([]string, 0)
+	if cs.Failure != nil {
+		steps = append(steps, strings.TrimSpace(cs.Failure.Type+" "+cs.Failure.Message), cs.Failure.Desc)
+	}
+	if output := testingService.GetUnitOutputText(cs); output != "" {
+		steps = append(steps, output)
+	}
+
+	bug := model.Bug{Title: cs.TestSuite + "." + cs.Title,
+		Module:      GetFirstNoEmptyVal(vari.ZenTaoBugFields.Modules),
+		Type:        GetFirstNoEmptyVal(vari.ZenTaoBugFields.Categories),
+		OpenedBuild: map[string]string{"0": "trunk"},
+		Severity:    GetFirstNoEmptyVal(vari.ZenTaoBugFields.Severities),
+		Pri:         GetFirstNoEmptyVal(vari.ZenTaoBugFields.Priorities),
+		Product:     strconv.Itoa(report.ProductId), Case: strconv.Itoa(cs.Cid),
+		Steps: strings.Join(steps, "\n"),
+		Uid:   uuid.NewV4().String(), CaseVersion: "0", OldTaskID: "0",
+		Files: testingService.LoadAttachments(cs.Attachments),
+	}
+
+	return bug
+}
+
 func CommitBug() (bool, string) {
 	bug := vari.CurrBug
 	stepIds := vari.CurrBugStepIds
 
 	conf := configUtils.ReadCurrConfig()
-	Login(conf.Url, conf.Account, conf.Password)
+	if err := Login(conf.Url, conf.Account, conf.Password); err != nil {
+		return false, client.GetErrorMsg(err)
+	}
 
 	productId := bug.Product
 	bug.Steps = strings.Replace(bug.Steps, " ", "&nbsp;", -1)
 
+	if zentaoUtils.UseRestApi() {
+		if err := commitBugRest(conf.Url, bug); err != nil {
+			return false, client.GetErrorMsg(err)
+		}
+		return true, i118Utils.I118Prt.Sprintf("success_to_report_bug", bug.Case)
+	}
+
 	// bug-create-1-0-caseID=1,version=3,resultID=93,runID=0,stepIdList=9_12_
 	// bug-create-1-0-caseID=1,version=3,resultID=84,runID=6,stepIdList=9_12_,testtask=2,projectID=1,buildID=1
 	extras := fmt.Sprintf("caseID=%s,version=0,resultID=0,runID=0,stepIdList=%s",
@@ -93,9 +143,9 @@ func CommitBug() (bool, string) {
 	params = ""
 	url := conf.Url + zentaoUtils.GenApiUri("bug", "create", params)
 
-	body, ok := client.PostObject(url, bug, true)
-	if !ok {
-		return false, ""
+	body, err := client.PostObject(url, bug, true)
+	if err != nil {
+		return false, client.GetErrorMsg(err)
 	}
 
 	json, err1 := simplejson.NewJson([]byte(body))
@@ -18,8 +18,23 @@ import (
 
 func GetBugFiledOptions(productId int) {
 	conf := configUtils.ReadCurrConfig()
-	Login(conf.Url, conf.Account, conf.Password)
+	if err := Login(conf.Url, conf.Account, conf.Password); err != nil {
+		return // the error is reported when committing bug
+	}
+
+	if zentaoUtils.UseRestApi() {
+		vari.ZenTaoBugFields = getRestBugFieldOptions(conf, productId)
+		return
+	}
+
+	bugFields, err := getBugFieldOptions(conf.Url, productId)
+	if err == nil {
+		vari.ZenTaoBugFields = bugFields
+	}
+}
 
+// getBugFieldOptions reads options of product by the json view, which needs a session
+func getBugFieldOptions(baseUrl string, productId int) (model.ZentaoBugFields, error) {
 	// $productID, $projectID = 0
 	params := ""
 	if vari.RequestType == constant.RequestTypePathInfo {
@@ -28,36 +43,36 @@ func GetBugFiledOptions(productId int) {
 		params = fmt.Sprintf("productID=%d", productId)
 	}
 
-	url := conf.Url + zentaoUtils.GenApiUri("bug", "ajaxGetBugFieldOptions", params)
-	dataStr, ok := client.Get(url)
+	url := baseUrl + zentaoUtils.GenApiUri("bug", "ajaxGetBugFieldOptions", params)
+	dataStr, err := client.Get(url)
 
 	bugFields := model.ZentaoBugFields{}
+	if err != nil {
+		return bugFields, err
+	}
 
-	if ok {
-		jsonData, err := simplejson.NewJson([]byte(dataStr))
-
-		if err == nil {
-			mp, _ := jsonData.Get("modules").Map()
-			bugFields.Modules = fieldMapToListOrderByInt(mp)
-
-			mp, _ = jsonData.Get("categories").Map()
-			bugFields.Categories = fieldMapToListOrderByStr(mp, false)
+	jsonData, err := simplejson.NewJson([]byte(dataStr))
+	if err == nil {
+		mp, _ := jsonData.Get("modules").Map()
+		bugFields.Modules = fieldMapToListOrderByInt(mp)
 
-			mp, _ = jsonData.Get("versions").Map()
-			bugFields.Versions = fieldMapToListOrderByStr(mp, true)
+		mp, _ = jsonData.Get("categories").Map()
+		bugFields.Categories = fieldMapToListOrderByStr(mp, false)
 
-			mp, _ = jsonData.Get("severities").Map()
-			bugFields.Severities = fieldMapToListOrderByInt(mp)
+		mp, _ = jsonData.Get("versions").Map()
+		bugFields.Versions = fieldMapToListOrderByStr(mp, true)
 
-			arr, _ := jsonData.Get("priorities").Array()
-			bugFields.Priorities = fieldArrToListKeyStr(arr, true)
+		mp, _ = jsonData.Get("severities").Map()
+		bugFields.Severities = fieldMapToListOrderByInt(mp)
 
-		} else {
-			logUtils.PrintToCmd(err.Error(), color.FgRed)
-		}
+		arr, _ := jsonData.Get("priorities").Array()
+		bugFields.Priorities = fieldArrToListKeyStr(arr, true)
 
-		vari.ZenTaoBugFields = bugFields
+	} else {
+		logUtils.PrintToCmd(err.Error(), color.FgRed)
 	}
+
+	return bugFields, nil
 }
 
 //func GetCaseModules(productId string) {
@@ -0,0 +1,283 @@
+package zentaoService
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bitly/go-simplejson"
+	"github.com/easysoft/zentaoatf/src/model"
+	"github.com/easysoft/zentaoatf/src/service/client"
+	"github.com/easysoft/zentaoatf/src/utils/vari"
+	zentaoUtils "github.com/easysoft/zentaoatf/src/utils/zentao"
+	"github.com/emirpasic/gods/maps"
+)
+
+// requests to restful api v1 of zentao, used instead of the json views if zentaoUtils.UseRestApi
+
+const restPageSize = 1000
+
+func loginRest(baseUrl string, account string, password string) error {
+	if vari.ZentaoToken != "" {
+		return nil
+	}
+
+	url := baseUrl + zentaoUtils.GenRestApiUri("tokens")
+	body, err := client.PostRest(url, map[string]string{"account": account, "password": password})
+	if err != nil {
+		return err
+	}
+
+	if json, err := simplejson.NewJson([]byte(body)); err == nil {
+		vari.ZentaoToken, _ = json.Get("token").String()
+	}
+	if vari.ZentaoToken == "" {
+		return &client.ZentaoError{Method: "POST", Url: url, StatusCode: http.StatusOK, Login: true,
+			Message: "no token in response"}
+	}
+
+	return nil
+}
+
+// listCaseByProductRest reads cases of product page by page, and the ones in module and its descendants
+// if moduleId is not empty, the same as browsing by module of json view.
+func listCaseByProductRest(baseUrl string, productId string, moduleId string) ([]model.TestCase, error) {
+	cases, err := listRestCasesOfProduct(baseUrl, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	var moduleIds map[int]bool
+	if moduleId != "" {
+		modules, err := listRestModules(baseUrl, productId, "case")
+		if err != nil {
+			return nil, err
+		}
+
+		id, _ := strconv.Atoi(moduleId)
+		moduleIds = map[int]bool{id: true}
+		getRestModuleDescendants(modules, id, false, moduleIds)
+	}
+
+	caseArr := make([]model.TestCase, 0)
+	for _, cs := range cases {
+		if moduleIds != nil && !moduleIds[cs.Module] {
+			continue
+		}
+
+		tc, err := getRestCaseWithSteps(baseUrl, cs.Id, cs)
+		if err != nil {
+			return nil, err
+		}
+		caseArr = append(caseArr, tc)
+	}
+
+	return caseArr, nil
+}
+
+// listRestCasesOfProduct reads cases of product page by page, without steps
+func listRestCasesOfProduct(baseUrl string, productId string) ([]model.RestCase, error) {
+	cases := make([]model.RestCase, 0)
+	for page := 1; ; page++ {
+		uri := fmt.Sprintf("products/%s/testcases?limit=%d&page=%d", productId, restPageSize, page)
+		dataStr, err := client.GetRest(baseUrl + zentaoUtils.GenRestApiUri(uri))
+		if err != nil {
+			return nil, err
+		}
+
+		var list model.RestCaseList
+		json.Unmarshal([]byte(dataStr), &list)
+
+		cases = append(cases, list.Testcases...)
+		if len(list.Testcases) == 0 || len(cases) >= list.Total {
+			break
+		}
+	}
+
+	return cases, nil
+}
+
+// listRestModules reads the module tree of product, typ is case or bug
+func listRestModules(baseUrl string, productId string, typ string) ([]model.RestModule, error) {
+	uri := fmt.Sprintf("modules?type=%s&id=%s", typ, productId)
+	dataStr, err := client.GetRest(baseUrl + zentaoUtils.GenRestApiUri(uri))
+	if err != nil {
+		return nil, err
+	}
+
+	var list model.RestModuleList
+	json.Unmarshal([]byte(dataStr), &list)
+
+	return list.Modules, nil
+}
+
+// getRestModuleDescendants puts ids of modules under module id into ids
+func getRestModuleDescendants(modules []model.RestModule, id int, isDescendant bool, ids map[int]bool) {
+	for _, module := range modules {
+		if isDescendant {
+			ids[module.Id] = true
+		}
+		getRestModuleDescendants(module.Children, id, isDescendant || module.Id == id, ids)
+	}
+}
+
+func listCaseBySuiteRest(baseUrl string, suiteId string) ([]model.TestCase, error) {
+	dataStr, err := client.GetRest(baseUrl + zentaoUtils.GenRestApiUri("testsuites/"+suiteId))
+	if err != nil {
+		return nil, err
+	}
+
+	var suite model.RestSuite
+	json.Unmarshal([]byte(dataStr), &suite)
+
+	caseArr := make([]model.TestCase, 0)
+	for _, cs := range suite.Testcases {
+		tc, err := getRestCaseWithSteps(baseUrl, cs.Id, cs)
+		if err != nil {
+			return nil, err
+		}
+		caseArr = append(caseArr, tc)
+	}
+
+	return caseArr, nil
+}
+
+func listCaseByTaskRest(baseUrl string, taskId string) ([]model.TestCase, error) {
+	dataStr, err := client.GetRest(baseUrl + zentaoUtils.GenRestApiUri("testtasks/"+taskId))
+	if err != nil {
+		return nil, err
+	}
+
+	var task model.RestTask
+	json.Unmarshal([]byte(dataStr), &task)
+
+	caseArr := make([]model.TestCase, 0)
+	for _, run := range task.Testcases {
+		caseId := run.Case
+		if caseId == 0 { // id is the case id if the item is not a run
+			caseId = run.Id
+		}
+
+		cs := model.RestCase{Id: caseId, Product: run.Product, Module: run.Module, Title: run.Title}
+		tc, err := getRestCaseWithSteps(baseUrl, caseId, cs)
+		if err != nil {
+			return nil, err
+		}
+		caseArr = append(caseArr, tc)
+	}
+
+	return caseArr, nil
+}
+
+func getRestCaseWithSteps(baseUrl string, caseId int, cs model.RestCase) (model.TestCase, error) {
+	csWithSteps, err := getCaseByIdRest(baseUrl, strconv.Itoa(caseId))
+
+	return model.TestCase{Id: strconv.Itoa(caseId), Product: strconv.Itoa(cs.Product),
+		Module: strconv.Itoa(cs.Module), Title: cs.Title, StepArr: genCaseSteps(csWithSteps)}, err
+}
+
+// steps are put in the map by id, like the ones returned by testcase-view
+func getCaseByIdRest(baseUrl string, caseId string) (model.TestCase, error) {
+	dataStr, err := client.GetRest(baseUrl + zentaoUtils.GenRestApiUri("testcases/"+caseId))
+	if err != nil {
+		return model.TestCase{}, err
+	}
+
+	var cs model.RestCase
+	json.Unmarshal([]byte(dataStr), &cs)
+
+	steps := map[int]model.TestStep{}
+	for _, step := range cs.Steps {
+		steps[step.Id] = model.TestStep{Id: strconv.Itoa(step.Id), Parent: strconv.Itoa(step.Parent),
+			Type: step.Type, Desc: step.Desc, Expect: step.Expect}
+	}
+
+	return model.TestCase{Id: strconv.Itoa(cs.Id), Product: strconv.Itoa(cs.Product),
+		Module: strconv.Itoa(cs.Module), Title: cs.Title, Steps: steps}, nil
+}
+
+// genRestCaseObj returns case to update, steps are listed in the order of script
+func genRestCaseObj(title string, stepMap maps.Map, stepTypeMap maps.Map, expectMap maps.Map) map[string]interface{} {
+	steps := make([]map[string]string, 0)
+	for _, key := range stepMap.Keys() {
+		desc, _ := stepMap.Get(key)
+		typ, _ := stepTypeMap.Get(key)
+		expect, _ := expectMap.Get(key)
+
+		step := map[string]string{"desc": strings.TrimSpace(fmt.Sprint(desc)), "type": "step", "expect": ""}
+		if typ != nil {
+			step["type"] = strings.TrimSpace(typ.(string))
+		}
+		if expect != nil {
+			step["expect"] = strings.TrimSpace(expect.(string))
+		}
+		steps = append(steps, step)
+	}
+
+	return map[string]interface{}{"title": title, "steps": steps}
+}
+
+// getRestBugFieldOptions reads options of product by the json view with session, since restful api provides no options of bug,
+// if failed, modules are read by restful api, and the default options of zentao are used for the others.
+func getRestBugFieldOptions(conf model.Config, productId int) model.ZentaoBugFields {
+	if err := loginSession(conf.Url, conf.Account, conf.Password); err == nil {
+		bugFields, err := getBugFieldOptions(conf.Url, productId)
+		if err == nil && len(bugFields.Categories) > 0 {
+			return bugFields
+		}
+	}
+
+	bugFields := model.ZentaoBugFields{}
+
+	bugFields.Modules = []model.Option{{Id: "0", Name: "/"}}
+	if modules, err := listRestModules(conf.Url, strconv.Itoa(productId), "bug"); err == nil {
+		addRestModuleOptions(modules, "/", &bugFields.Modules)
+	}
+
+	bugFields.Versions = []model.Option{{Id: "trunk", Name: "trunk"}}
+	for _, typ := range []string{"codebug", "config", "install", "security", "performance",
+		"standard", "automation", "designdefect", "others"} {
+		bugFields.Categories = append(bugFields.Categories, model.Option{Id: typ, Name: typ})
+	}
+	for i := 1; i <= 4; i++ {
+		bugFields.Severities = append(bugFields.Severities, model.Option{Id: strconv.Itoa(i), Name: strconv.Itoa(i)})
+		bugFields.Priorities = append(bugFields.Priorities, model.Option{Id: strconv.Itoa(i), Name: strconv.Itoa(i)})
+	}
+
+	return bugFields
+}
+
+// module options are named with the path, like /parent/child
+func addRestModuleOptions(modules []model.RestModule, parentPath string, options *[]model.Option) {
+	for _, module := range modules {
+		name := parentPath + module.Name
+		*options = append(*options, model.Option{Id: strconv.Itoa(module.Id), Name: name})
+		addRestModuleOptions(module.Children, name+"/", options)
+	}
+}
+
+func commitBugRest(baseUrl string, bug model.Bug) error {
+	builds := make([]string, 0)
+	for _, build := range bug.OpenedBuild {
+		builds = append(builds, build)
+	}
+	sort.Strings(builds)
+
+	severity, _ := strconv.Atoi(bug.Severity)
+	pri, _ := strconv.Atoi(bug.Pri)
+	module, _ := strconv.Atoi(bug.Module)
+	caseId, _ := strconv.Atoi(bug.Case)
+
+	requestObj := map[string]interface{}{"title": bug.Title, "type": bug.Type, "severity": severity, "pri": pri,
+		"module": module, "openedBuild": builds, "case": caseId, "steps": bug.Steps}
+	if len(bug.Files) > 0 {
+		requestObj["files"] = bug.Files
+	}
+
+	url := baseUrl + zentaoUtils.GenRestApiUri(fmt.Sprintf("products/%s/bugs", bug.Product))
+	_, err := client.PostRest(url, requestObj)
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/bitly/go-simplejson"
 	"github.com/easysoft/zentaoatf/src/model"
 	"github.com/easysoft/zentaoatf/src/service/client"
+	testingService "github.com/easysoft/zentaoatf/src/service/testing"
 	configUtils "github.com/easysoft/zentaoatf/src/utils/config"
 	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
 	logUtils "github.com/easysoft/zentaoatf/src/utils/log"
@@ -30,7 +31,7 @@ func CommitTestResult(report model.TestReport, testTaskId int) {
 	}
 
 	conf := configUtils.ReadCurrConfig()
-	Login(conf.Url, conf.Account, conf.Password)
+	err := Login(conf.Url, conf.Account, conf.Password)
 
 	report.ZentaoData = os.Getenv("ZENTAO_DATA")
 	report.BuildUrl = os.Getenv("BUILD_URL")
@@ -41,12 +42,28 @@ func CommitTestResult(report model.TestReport, testTaskId int) {
 		report.ProductId = report.FuncResult[0].ProductId
 	}
 
-	url := conf.Url + zentaoUtils.GenApiUri("ci", "commitResult", "")
-    // url = color.RedString(url)
-    // logUtils.Screen(url)
-	resp, ok := client.PostObject(url, report, false)
+	// each row of a data-driven case is committed as a separate run
+	report.FuncResult = flattenDataRowResults(report.FuncResult)
+	// small attachments of unit tests are uploaded with the result
+	report.UnitResult = loadUnitAttachments(report.UnitResult)
 
-	if ok {
+	var resp string
+	if err == nil {
+		report.UnitResult = checkUnitCaseIds(conf.Url, strconv.Itoa(report.ProductId), report.UnitResult)
+
+		if zentaoUtils.UseRestApi() {
+			url := conf.Url + zentaoUtils.GenRestApiUri("ciresults")
+			resp, err = client.PostRestResult(url, report)
+		} else {
+			url := conf.Url + zentaoUtils.GenApiUri("ci", "commitResult", "")
+			// url = color.RedString(url)
+			// logUtils.Screen(url)
+			resp, err = client.PostResultObject(url, report)
+		}
+	}
+
+	ok := err == nil
+	if ok && !zentaoUtils.UseRestApi() { // result of restful api is told by status code
 		json, err1 := simplejson.NewJson([]byte(resp))
 		if err1 == nil {
 			result, err2 := json.Get("result").String()
@@ -63,7 +80,9 @@ func CommitTestResult(report model.TestReport, testTaskId int) {
 		msg += color.GreenString(i118Utils.I118Prt.Sprintf("success_to_submit_test_result"))
 	} else {
 		msg = i118Utils.I118Prt.Sprintf("fail_to_submit_test_result")
-		if strings.Index(resp, "login") > -1 {
+		if err != nil {
+			msg += "\n" + client.GetErrorMsg(err)
+		} else if strings.Index(resp, "login") > -1 {
 			msg = i118Utils.I118Prt.Sprintf("fail_to_login")
 		}
 		msg = color.RedString(msg)
@@ -72,7 +91,55 @@ func CommitTestResult(report model.TestReport, testTaskId int) {
 	logUtils.Screen(msg)
 	logUtils.Screen(logUtils.GetWholeLine("=", "=") + "\n")
 
-	if report.Fail > 0 || !ok {
+	if report.Fail > 0 || report.Error > 0 || !ok {
 		os.Exit(1)
 	}
 }
+
+func flattenDataRowResults(results []model.FuncResult) []model.FuncResult {
+	ret := make([]model.FuncResult, 0)
+	for _, cs := range results {
+		if len(cs.SubResults) > 0 {
+			ret = append(ret, cs.SubResults...)
+		} else {
+			ret = append(ret, cs)
+		}
+	}
+
+	return ret
+}
+
+// cases not in product are removed from results, since the id may be read from a name like test_login_case1 by mistake
+func checkUnitCaseIds(baseUrl string, productId string, results []model.UnitResult) []model.UnitResult {
+	hasCase := false
+	for _, cs := range results {
+		hasCase = hasCase || cs.Cid > 0
+	}
+	if !hasCase {
+		return results
+	}
+
+	ids, err := getCaseIdsByProduct(baseUrl, productId)
+	if err != nil {
+		return results
+	}
+
+	for idx, cs := range results {
+		if cs.Cid > 0 && !ids[cs.Cid] {
+			logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("unit_case_not_found", cs.Cid, cs.Title, productId), color.FgYellow)
+			results[idx].Cid = 0
+		}
+	}
+
+	return results
+}
+
+func loadUnitAttachments(results []model.UnitResult) []model.UnitResult {
+	ret := make([]model.UnitResult, 0)
+	for _, cs := range results {
+		cs.Attachments = testingService.LoadAttachments(cs.Attachments)
+		ret = append(ret, cs)
+	}
+
+	return ret
+}
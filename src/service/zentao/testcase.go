@@ -9,6 +9,7 @@ import (
 
 	"github.com/easysoft/zentaoatf/src/model"
 	"github.com/easysoft/zentaoatf/src/service/client"
+	testingService "github.com/easysoft/zentaoatf/src/service/testing"
 	commonUtils "github.com/easysoft/zentaoatf/src/utils/common"
 	configUtils "github.com/easysoft/zentaoatf/src/utils/config"
 	constant "github.com/easysoft/zentaoatf/src/utils/const"
@@ -18,35 +19,39 @@ import (
 	"github.com/easysoft/zentaoatf/src/utils/vari"
 	zentaoUtils "github.com/easysoft/zentaoatf/src/utils/zentao"
 	"github.com/emirpasic/gods/maps"
+	"github.com/fatih/color"
 )
 
-func LoadTestCases(productIdStr, moduleIdStr, suiteIdStr, taskIdStr string) (testcases []model.TestCase, loginFail bool) {
+func LoadTestCases(productIdStr, moduleIdStr, suiteIdStr, taskIdStr string) (testcases []model.TestCase, err error) {
 	config := configUtils.ReadCurrConfig()
 
-	ok := Login(config.Url, config.Account, config.Password)
+	err = Login(config.Url, config.Account, config.Password)
 	// ok := GetCookie(config.Url, config.Account, config.Password)
-	if !ok {
-		loginFail = true
+	if err != nil {
 		return
 	}
 
 	if moduleIdStr != "" {
-		testcases = ListCaseByModule(config.Url, productIdStr, moduleIdStr)
+		testcases, err = ListCaseByModule(config.Url, productIdStr, moduleIdStr)
 	} else if suiteIdStr != "" {
 
-		testcases = ListCaseBySuite(config.Url, suiteIdStr)
+		testcases, err = ListCaseBySuite(config.Url, suiteIdStr)
 	} else if taskIdStr != "" {
-		testcases = ListCaseByTask(config.Url, taskIdStr)
+		testcases, err = ListCaseByTask(config.Url, taskIdStr)
 	} else if productIdStr != "" {
-		testcases = ListCaseByProduct(config.Url, productIdStr)
+		testcases, err = ListCaseByProduct(config.Url, productIdStr)
 	} else {
-		logUtils.PrintUsage()
+		logUtils.PrintUsage(testingService.GetUnitTestTypes())
 	}
 
 	return
 }
 
-func ListCaseByProduct(baseUrl string, productId string) []model.TestCase {
+func ListCaseByProduct(baseUrl string, productId string) ([]model.TestCase, error) {
+	if zentaoUtils.UseRestApi() {
+		return listCaseByProductRest(baseUrl, productId, "")
+	}
+
 	// $productID=productId, $branch = '', $browseType = 'byModule', $param=moduleId,
 	// $orderBy='id_desc', $recTotal=0, $recPerPage=10000, $pageID=1)
 
@@ -58,9 +63,9 @@ func ListCaseByProduct(baseUrl string, productId string) []model.TestCase {
 	}
 
 	url := baseUrl + zentaoUtils.GenApiUri("testcase", "browse", params)
-	dataStr, ok := client.Get(url)
+	dataStr, err := client.Get(url)
 
-	if ok {
+	if err == nil {
 		var product model.Product
 		json.Unmarshal([]byte(dataStr), &product)
 
@@ -68,19 +73,26 @@ func ListCaseByProduct(baseUrl string, productId string) []model.TestCase {
 		for _, cs := range product.Cases {
 			caseId := cs.Id
 
-			csWithSteps := GetCaseById(baseUrl, caseId)
+			csWithSteps, err := GetCaseById(baseUrl, caseId)
+			if err != nil {
+				return nil, err
+			}
 			stepArr := genCaseSteps(csWithSteps)
 			caseArr = append(caseArr, model.TestCase{Id: caseId, Product: cs.Product, Module: cs.Module,
 				Title: cs.Title, StepArr: stepArr})
 		}
 
-		return caseArr
+		return caseArr, nil
 	}
 
-	return nil
+	return nil, err
 }
 
-func ListCaseByModule(baseUrl string, productId string, moduleId string) []model.TestCase {
+func ListCaseByModule(baseUrl string, productId string, moduleId string) ([]model.TestCase, error) {
+	if zentaoUtils.UseRestApi() {
+		return listCaseByProductRest(baseUrl, productId, moduleId)
+	}
+
 	// $productID=productId, $branch = '', $browseType = 'byModule', $param=moduleId,
 	// $orderBy='id_desc', $recTotal=0, $recPerPage=10000, $pageID=1)
 
@@ -92,9 +104,9 @@ func ListCaseByModule(baseUrl string, productId string, moduleId string) []model
 	}
 
 	url := baseUrl + zentaoUtils.GenApiUri("testcase", "browse", params)
-	dataStr, ok := client.Get(url)
+	dataStr, err := client.Get(url)
 
-	if ok {
+	if err == nil {
 		var module model.Module
 		json.Unmarshal([]byte(dataStr), &module)
 
@@ -102,20 +114,27 @@ func ListCaseByModule(baseUrl string, productId string, moduleId string) []model
 		for _, cs := range module.Cases {
 			caseId := cs.Id
 
-			csWithSteps := GetCaseById(baseUrl, caseId)
+			csWithSteps, err := GetCaseById(baseUrl, caseId)
+			if err != nil {
+				return nil, err
+			}
 			stepArr := genCaseSteps(csWithSteps)
 
 			caseArr = append(caseArr, model.TestCase{Id: caseId, Product: cs.Product, Module: cs.Module,
 				Title: cs.Title, StepArr: stepArr})
 		}
 
-		return caseArr
+		return caseArr, nil
 	}
 
-	return nil
+	return nil, err
 }
 
-func ListCaseBySuite(baseUrl string, suiteId string) []model.TestCase {
+func ListCaseBySuite(baseUrl string, suiteId string) ([]model.TestCase, error) {
+	if zentaoUtils.UseRestApi() {
+		return listCaseBySuiteRest(baseUrl, suiteId)
+	}
+
 	// $suiteID, $orderBy = 'id_desc', $recTotal = 0, $recPerPage = 20, $pageID = 1
 
 	params := ""
@@ -126,9 +145,9 @@ func ListCaseBySuite(baseUrl string, suiteId string) []model.TestCase {
 	}
 
 	url := baseUrl + zentaoUtils.GenApiUri("testsuite", "view", params)
-	dataStr, ok := client.Get(url)
+	dataStr, err := client.Get(url)
 
-	if ok {
+	if err == nil {
 		var suite model.TestSuite
 		json.Unmarshal([]byte(dataStr), &suite)
 
@@ -136,20 +155,27 @@ func ListCaseBySuite(baseUrl string, suiteId string) []model.TestCase {
 		for _, cs := range suite.Cases {
 			caseId := cs.Id
 
-			csWithSteps := GetCaseById(baseUrl, caseId)
+			csWithSteps, err := GetCaseById(baseUrl, caseId)
+			if err != nil {
+				return nil, err
+			}
 			stepArr := genCaseSteps(csWithSteps)
 
 			caseArr = append(caseArr, model.TestCase{Id: caseId, Product: cs.Product, Module: cs.Module,
 				Title: cs.Title, StepArr: stepArr})
 		}
 
-		return caseArr
+		return caseArr, nil
 	}
 
-	return nil
+	return nil, err
 }
 
-func ListCaseByTask(baseUrl string, taskId string) []model.TestCase {
+func ListCaseByTask(baseUrl string, taskId string) ([]model.TestCase, error) {
+	if zentaoUtils.UseRestApi() {
+		return listCaseByTaskRest(baseUrl, taskId)
+	}
+
 	// $taskID, $browseType = 'all', $param = 0,
 	// $orderBy = 'id_desc', $recTotal = 0, $recPerPage = 20, $pageID = 1
 
@@ -161,9 +187,9 @@ func ListCaseByTask(baseUrl string, taskId string) []model.TestCase {
 	}
 
 	url := baseUrl + zentaoUtils.GenApiUri("testtask", "cases", params)
-	dataStr, ok := client.Get(url)
+	dataStr, err := client.Get(url)
 
-	if ok {
+	if err == nil {
 		var task model.TestTask
 		json.Unmarshal([]byte(dataStr), &task)
 
@@ -171,17 +197,20 @@ func ListCaseByTask(baseUrl string, taskId string) []model.TestCase {
 		for _, cs := range task.Runs {
 			caseId := cs.Case
 
-			csWithSteps := GetCaseById(baseUrl, caseId)
+			csWithSteps, err := GetCaseById(baseUrl, caseId)
+			if err != nil {
+				return nil, err
+			}
 			stepArr := genCaseSteps(csWithSteps)
 
 			caseArr = append(caseArr, model.TestCase{Id: caseId, Product: cs.Product, Module: cs.Module,
 				Title: cs.Title, StepArr: stepArr})
 		}
 
-		return caseArr
+		return caseArr, nil
 	}
 
-	return nil
+	return nil, err
 }
 
 func genCaseSteps(csWithSteps model.TestCase) (ret []model.TestStep) {
@@ -200,7 +229,11 @@ func genCaseSteps(csWithSteps model.TestCase) (ret []model.TestStep) {
 	return
 }
 
-func GetCaseById(baseUrl string, caseId string) model.TestCase {
+func GetCaseById(baseUrl string, caseId string) (model.TestCase, error) {
+	if zentaoUtils.UseRestApi() {
+		return getCaseByIdRest(baseUrl, caseId)
+	}
+
 	// $caseID, $version = 0, $from = 'testcase', $taskID = 0
 
 	params := ""
@@ -211,28 +244,66 @@ func GetCaseById(baseUrl string, caseId string) model.TestCase {
 	}
 
 	url := baseUrl + zentaoUtils.GenApiUri("testcase", "view", params)
-	dataStr, ok := client.Get(url)
+	dataStr, err := client.Get(url)
 
-	if ok {
+	if err == nil {
 		var csw model.TestCaseWrapper
 		json.Unmarshal([]byte(dataStr), &csw)
 
 		cs := csw.Case
-		return cs
+		return cs, nil
 	}
 
-	return model.TestCase{}
+	return model.TestCase{}, err
+}
+
+// getCaseIdsByProduct returns ids of cases in product, steps are not loaded
+func getCaseIdsByProduct(baseUrl string, productId string) (map[int]bool, error) {
+	ids := map[int]bool{}
+
+	if zentaoUtils.UseRestApi() {
+		cases, err := listRestCasesOfProduct(baseUrl, productId)
+		for _, cs := range cases {
+			ids[cs.Id] = true
+		}
+		return ids, err
+	}
+
+	params := ""
+	if vari.RequestType == constant.RequestTypePathInfo {
+		params = fmt.Sprintf("%s--byModule-all-id_asc-0-10000-1", productId)
+	} else {
+		params = fmt.Sprintf("productID=%s&branch=&browseType=byModule&param=0&orderBy=id_desc&recTotal=0&recPerPage=10000", productId)
+	}
+
+	dataStr, err := client.Get(baseUrl + zentaoUtils.GenApiUri("testcase", "browse", params))
+	if err != nil {
+		return ids, err
+	}
+
+	var product model.Product
+	json.Unmarshal([]byte(dataStr), &product)
+	for _, cs := range product.Cases {
+		id, _ := strconv.Atoi(cs.Id)
+		ids[id] = true
+	}
+
+	return ids, nil
 }
 
 func GetCaseIdsBySuite(suiteId string, idMap *map[int]string) {
 	config := configUtils.ReadCurrConfig()
 
-	ok := Login(config.Url, config.Account, config.Password)
-	if !ok {
+	err := Login(config.Url, config.Account, config.Password)
+	if err != nil {
+		logUtils.PrintToWithColor(client.GetErrorMsg(err), color.FgRed)
 		return
 	}
 
-	testcases := ListCaseBySuite(config.Url, suiteId)
+	testcases, err := ListCaseBySuite(config.Url, suiteId)
+	if err != nil {
+		logUtils.PrintToWithColor(client.GetErrorMsg(err), color.FgRed)
+	}
 
 	for _, tc := range testcases {
 		id, _ := strconv.Atoi(tc.Id)
@@ -243,12 +314,16 @@ func GetCaseIdsBySuite(suiteId string, idMap *map[int]string) {
 func GetCaseIdsByTask(taskId string, idMap *map[int]string) {
 	config := configUtils.ReadCurrConfig()
 
-	ok := Login(config.Url, config.Account, config.Password)
-	if !ok {
+	err := Login(config.Url, config.Account, config.Password)
+	if err != nil {
+		logUtils.PrintToWithColor(client.GetErrorMsg(err), color.FgRed)
 		return
 	}
 
-	testcases := ListCaseByTask(config.Url, taskId)
+	testcases, err := ListCaseByTask(config.Url, taskId)
+	if err != nil {
+		logUtils.PrintToWithColor(client.GetErrorMsg(err), color.FgRed)
+	}
 
 	for _, tc := range testcases {
 		id, _ := strconv.Atoi(tc.Id)
@@ -259,8 +334,9 @@ func GetCaseIdsByTask(taskId string, idMap *map[int]string) {
 func CommitCase(caseId int, title string, stepMap maps.Map, stepTypeMap maps.Map, expectMap maps.Map) {
 	config := configUtils.ReadCurrConfig()
 
-	ok := Login(config.Url, config.Account, config.Password)
-	if !ok {
+	err := Login(config.Url, config.Account, config.Password)
+	if err != nil {
+		logUtils.PrintToWithColor(client.GetErrorMsg(err), color.FgRed)
 		return
 	}
 
@@ -278,6 +354,10 @@ func CommitCase(caseId int, title string, stepMap maps.Map, stepTypeMap maps.Map
 		"steps":    commonUtils.LinkedMapToMap(stepMap),
 		"stepType": commonUtils.LinkedMapToMap(stepTypeMap),
 		"expects":  commonUtils.LinkedMapToMap(expectMap)}
+	if zentaoUtils.UseRestApi() {
+		url = config.Url + zentaoUtils.GenRestApiUri(fmt.Sprintf("testcases/%d", caseId))
+		requestObj = genRestCaseObj(title, stepMap, stepTypeMap, expectMap)
+	}
 
 	json, _ := json.Marshal(requestObj)
 	logUtils.PrintToCmd(string(json), -1)
@@ -287,10 +367,16 @@ func CommitCase(caseId int, title string, stepMap maps.Map, stepTypeMap maps.Map
 	stdinUtils.InputForBool(&yes, true, "want_to_continue")
 
 	if yes {
-		_, ok = client.PostObject(url, requestObj, true)
+		if zentaoUtils.UseRestApi() {
+			_, err = client.PutRest(url, requestObj)
+		} else {
+			_, err = client.PostObject(url, requestObj, true)
+		}
 
-		if ok {
+		if err == nil {
 			logUtils.PrintTo(i118Utils.I118Prt.Sprintf("success_to_commit_case", caseId) + "\n")
+		} else {
+			logUtils.PrintToWithColor(client.GetErrorMsg(err), color.FgRed)
 		}
 	}
 }
@@ -28,6 +28,10 @@ var (
 
 	LogDir = fmt.Sprintf("log%s", string(os.PathSeparator))
 
+	HistoryDir     = fmt.Sprintf("history%s", string(os.PathSeparator))
+	HistoryIndex   = "index.json"
+	HistoryMaxRuns = 100
+
 	LeftWidth = 36
 	MinWidth  = 130
 	MinHeight = 36
@@ -35,16 +39,45 @@ var (
 	CmdViewHeight = 10
 
 	RequestTypePathInfo = "PATH_INFO"
+	RestApiPath         = "api.php/v1/"
+	RequestTimeout      = 30 // seconds, used if not set in config
+	RequestRetry        = 3
+
+	// minimal versions of each edition providing restful api v1, the key is the prefix of version
+	RestApiVersions = map[string]float64{"": 16, "biz": 6, "max": 2, "ipd": 1}
 
 	AutoTestTypes       = []string{"selenium", "appium"}
 	UnitTestTypeJunit   = "junit"
 	UnitTestTypeTestNG  = "testng"
 	UnitTestTypeRobot   = "robot"
 	UnitTestTypeCypress = "cypress"
-	UnitTestTypes       = []string{UnitTestTypeJunit, UnitTestTypeTestNG, UnitTestTypeRobot, UnitTestTypeCypress,
-		"phpunit", "pytest", "jest", "cppunit", "gtest", "qtest"}
-	UnitTestToolMvn   = "mvn"
-	UnitTestToolRobot = "robot"
+	UnitTestTypeGoTest  = "gotest"
+	UnitTestTypeNUnit   = "nunit"
+	UnitTestTypeXUnit   = "xunit"
+	UnitTestTypeMSTest  = "mstest"
+	UnitTestTypeTap     = "tap"
+	UnitTestTypeAuto    = "auto"
+	UnitTestToolMvn     = "mvn"
+	UnitTestToolRobot   = "robot"
+	UnitTestToolGradle  = "gradle"
+	UnitTestToolNpm     = "npm"
+	UnitTestToolPytest  = "pytest"
+	UnitTestToolCTest   = "ctest"
+	UnitTestToolDotnet  = "dotnet"
+
+	UnitTestResultPytest = fmt.Sprintf("test-results%spytest.xml", string(os.PathSeparator))
+	UnitTestResultCTest  = "ctest-results.xml"
+	UnitTestResultDotnet = "TestResults"
+	UnitTestResultRobot  = "output.xml"
+
+	OutputFormatTap = "tap"
+
+	MaxAttachmentSize = int64(1024 * 1024) // bigger attachments of unit test are not uploaded
+
+	CaseInfoExtraFields = []string{"timeout", "tags", "priority", "owner", "setup", "teardown", "data"}
+
+	HookSetupFile    = "_setup"
+	HookTeardownFile = "_teardown"
 
 	RunModeCommon  = "common"
 	RunModeServer  = "server"
@@ -45,6 +45,8 @@ const (
 	FAIL
 	SKIP
 	BLOCKED
+	TIMEOUT
+	ERROR
 )
 
 func (c ResultStatus) String() string {
@@ -57,6 +59,10 @@ func (c ResultStatus) String() string {
 		return "skip"
 	case BLOCKED:
 		return "blocked"
+	case TIMEOUT:
+		return "timeout"
+	case ERROR:
+		return "error"
 	}
 
 	return "UNKNOWN"
@@ -168,6 +168,16 @@ func GetZTFDir() (dir string, isDebug bool) { // where ztf command in
 	return
 }
 
+// runs are kept in history dir, since numbered log dirs are rotated into log/bak
+func GetHistoryDir() string {
+	path := vari.ExeDir + constant.HistoryDir
+	if vari.ServerWorkDir != "" {
+		path = vari.ServerWorkDir + constant.HistoryDir
+	}
+
+	return path
+}
+
 func GetLogDir() string {
 	path := vari.ExeDir + constant.LogDir
 	if vari.ServerWorkDir != "" {
@@ -38,10 +38,12 @@ func ColoredStatus(status string) string {
 	switch temp {
 	case "pass":
 		return color.GreenString(i118Utils.I118Prt.Sprintf(temp))
-	case "fail":
+	case "fail", "timeout":
 		return color.RedString(i118Utils.I118Prt.Sprintf(temp))
-	case "skip":
+	case "skip", "blocked":
 		return color.YellowString(i118Utils.I118Prt.Sprintf(temp))
+	case "error":
+		return color.MagentaString(i118Utils.I118Prt.Sprintf(temp))
 	}
 
 	return status
@@ -7,6 +7,7 @@ import (
 	constant "github.com/easysoft/zentaoatf/src/utils/const"
 	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
 	"github.com/fatih/color"
+	"github.com/mattn/go-colorable"
 	"os"
 	"regexp"
 	"strings"
@@ -17,7 +18,8 @@ var (
 	sampleFile = fmt.Sprintf("res%sdoc%ssample.txt", string(os.PathSeparator), string(os.PathSeparator))
 )
 
-func PrintUsage() {
+// PrintUsage prints usage and samples, unitTestTypes are the names of registered unit test parsers
+func PrintUsage(unitTestTypes []string) {
 	PrintToWithColor("Usage: ", color.FgCyan)
 
 	usage := fileUtils.ReadResData(usageFile)
@@ -26,6 +28,7 @@ func PrintUsage() {
 		exeFile += ".exe"
 	}
 	usage = fmt.Sprintf(usage, exeFile)
+	usage = strings.Replace(usage, "{unitTestTypes}", strings.Join(unitTestTypes, "、"), 1)
 	fmt.Printf("%s\n", usage)
 
 	PrintToWithColor("\nExample: ", color.FgCyan)
@@ -46,6 +49,11 @@ func PrintUsage() {
 	fmt.Printf("%s\n", sample)
 }
 
+// RedirectToStderr prints messages to stderr, to keep stdout for the machine readable output like tap
+func RedirectToStderr() {
+	color.Output = colorable.NewColorableStderr()
+}
+
 func PrintTo(str string) {
 	output := color.Output
 	fmt.Fprint(output, str+"\n")
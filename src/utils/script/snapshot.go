@@ -0,0 +1,163 @@
+package scriptUtils
+
+import (
+	"fmt"
+	constant "github.com/easysoft/zentaoatf/src/utils/const"
+	zentaoUtils "github.com/easysoft/zentaoatf/src/utils/zentao"
+	"regexp"
+	"strings"
+)
+
+// ReplaceExpectsInScript replaces the inline expects of steps in script header, expects is keyed by step numb like "1." or "2.1.",
+// the value's lines are joined with " | " and will be written as a multi-line expect.
+func ReplaceExpectsInScript(content string, lang string, expects map[string]string) string {
+	regStr := fmt.Sprintf(`(?smU)%s((?U:.*pid.*))\n(.*)%s`,
+		constant.LangCommentsRegxMap[lang][0], constant.LangCommentsRegxMap[lang][1])
+	arr := regexp.MustCompile(regStr).FindStringSubmatchIndex(content)
+	if len(arr) < 6 {
+		return content
+	}
+
+	infoRegx := regexp.MustCompile(`^\s*(` + strings.Join(constant.CaseInfoExtraFields, "|") + `)\s*=`)
+	lines := strings.Split(content[arr[4]:arr[5]], "\n")
+	ret := make([]string, 0)
+
+	// number steps in the same way as getStepNestedArr
+	groupNumb, childNumb := 0, 0
+	isFirst := true
+	inDataTable := false
+	for index := 0; index < len(lines); index++ {
+		line := lines[index]
+		lineTrim := strings.TrimSpace(line)
+		if infoRegx.MatchString(line) {
+			inDataTable = zentaoUtils.IsDataTableStart(line)
+			ret = append(ret, line)
+			continue
+		} else if inDataTable && zentaoUtils.IsDataTableLine(line) {
+			ret = append(ret, line)
+			continue
+		}
+		inDataTable = false
+
+		if lineTrim == "" || lineTrim == ">>" {
+			ret = append(ret, line)
+			continue
+		}
+
+		end := index + getMultiLineExpectIncrease(line, lines[index+1:])
+
+		numb := ""
+		if isFirst || strings.Index(line, " ") != 0 {
+			groupNumb++
+			childNumb = 0
+			numb = getNumbStr(groupNumb, -1)
+		} else if groupNumb > 0 {
+			childNumb++
+			numb = getNumbStr(groupNumb, childNumb)
+		}
+		isFirst = false
+
+		expect, ok := expects[numb]
+		if !ok {
+			ret = append(ret, lines[index:end+1]...)
+			index = end
+			continue
+		}
+
+		prefix := strings.TrimRight(line, " \t") + " "
+		if pos := strings.Index(line, ">>"); pos > -1 {
+			prefix = line[:pos]
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+		expectLines := strings.Split(expect, " | ")
+		if len(expectLines) == 1 {
+			ret = append(ret, prefix+">> "+expect)
+		} else {
+			ret = append(ret, prefix+">>")
+			for _, expectLine := range expectLines {
+				ret = append(ret, indent+"  "+expectLine)
+			}
+			ret = append(ret, indent+">>")
+		}
+
+		// skip the old closing >>
+		if end > index && end+1 < len(lines) && strings.TrimSpace(lines[end+1]) == ">>" {
+			end++
+		}
+		index = end
+	}
+
+	return content[:arr[4]] + strings.Join(ret, "\n") + content[arr[5]:]
+}
+
+// ReplaceExpectsInExpFile replaces the expects in independent .exp file, expects is keyed by the expect's index in file.
+func ReplaceExpectsInExpFile(content string, expects map[int]string) string {
+	lines := strings.Split(content, "\n")
+	ret := make([]string, 0)
+
+	// read expects in the same way as zentaoUtils.ReadExpectIndependentArr
+	numb := 0
+	for index := 0; index < len(lines); index++ {
+		line := strings.TrimSpace(lines[index])
+
+		if zentaoUtils.IsCheckpointLabel(line) {
+			ret = append(ret, lines[index])
+			continue
+		}
+
+		end := index
+		if line == ">>" {
+			for end+1 < len(lines) && strings.Index(lines[end+1], ">>") < 0 {
+				end++
+			}
+			if end == index { // no expect lines in it
+				ret = append(ret, lines[index])
+				continue
+			}
+
+			if end+1 < len(lines) && strings.TrimSpace(lines[end+1]) == ">>" {
+				end++
+			}
+		}
+
+		expect, ok := expects[numb]
+		numb++
+		if !ok {
+			ret = append(ret, lines[index:end+1]...)
+			index = end
+			continue
+		}
+
+		expectLines := strings.Split(expect, " | ")
+		if len(expectLines) == 1 {
+			ret = append(ret, expect)
+		} else {
+			ret = append(ret, ">>")
+			ret = append(ret, expectLines...)
+			ret = append(ret, ">>")
+		}
+		index = end
+	}
+
+	return strings.Join(ret, "\n")
+}
+
+// the same as parserNextLines, return the offset of last line of a multi-line expect
+func getMultiLineExpectIncrease(str string, nextLines []string) (increase int) {
+	arr := strings.Split(str, ">>")
+	if len(arr) < 2 || strings.TrimSpace(arr[1]) != "" {
+		return
+	}
+
+	for index, line := range nextLines {
+		if strings.TrimSpace(line) == ">>" {
+			return index
+		}
+		if strings.Index(line, ">>") > -1 {
+			return 0
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+// +build !windows
+
+package shellUtils
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// start script in a new process group, so that all its children can be killed together
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killProcessTree(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
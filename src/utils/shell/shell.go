@@ -3,6 +3,7 @@ package shellUtils
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	commonUtils "github.com/easysoft/zentaoatf/src/utils/common"
 	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
@@ -14,6 +15,8 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 func ExeSysCmd(cmdStr string) (string, error) {
@@ -51,7 +54,52 @@ func ExeAppInDir(cmdStr string, dir string) (string, error) {
 	return out.String(), err
 }
 
+// run a setup or teardown hook, return its stdout and stderr together,
+// the whole process tree is killed if it runs more than timeout seconds, 0 means no limit.
+func ExeHook(cmdStr string, dir string, timeout int) (string, error) {
+	var cmd *exec.Cmd
+	if commonUtils.IsWin() {
+		cmd = exec.Command("cmd", "/C", cmdStr)
+	} else {
+		cmd = exec.Command("/bin/bash", "-c", cmdStr)
+	}
+
+	if dir != "" {
+		cmd.Dir = dir
+	}
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+
+	var timeoutFlag int32
+	if timeout > 0 {
+		timer := time.AfterFunc(time.Duration(timeout)*time.Second, func() {
+			atomic.StoreInt32(&timeoutFlag, 1)
+			killProcessTree(cmd)
+		})
+		defer timer.Stop()
+	}
+
+	err := cmd.Wait()
+	if atomic.LoadInt32(&timeoutFlag) == 1 {
+		err = errors.New(i118Utils.I118Prt.Sprintf("hook_timeout", timeout))
+	}
+
+	return out.String(), err
+}
+
 func ExeAppWithOutput(cmdStr string) []string {
+	return ExeAppWithOutputFilter(cmdStr, nil)
+}
+
+// ExeAppWithOutputFilter prints the text returned by filter for each line, or the line itself if filter is nil
+func ExeAppWithOutputFilter(cmdStr string, filter func(line string) (string, bool)) []string {
 	var cmd *exec.Cmd
 	if commonUtils.IsWin() {
 		cmd = exec.Command("cmd", "/C", cmdStr)
@@ -85,7 +133,11 @@ func ExeAppWithOutput(cmdStr string) []string {
 		if err2 != nil || io.EOF == err2 {
 			break
 		}
-		logUtils.Screen(strings.TrimRight(line, "\n"))
+		if filter == nil {
+			logUtils.Screen(strings.TrimRight(line, "\n"))
+		} else if text, ok := filter(line); ok {
+			logUtils.Screen(text)
+		}
 		output = append(output, line)
 	}
 
@@ -95,6 +147,13 @@ func ExeAppWithOutput(cmdStr string) []string {
 }
 
 func ExecScriptFile(filePath string) (string, string) {
+	out, errOut, _ := ExecScriptFileWithTimeout(filePath, 0, nil)
+	return out, errOut
+}
+
+// kill the whole process tree of script if it runs more than timeout seconds, 0 means no limit,
+// env like "NAME=value" are added to the environment of script.
+func ExecScriptFileWithTimeout(filePath string, timeout int, env []string) (out string, errOut string, isTimeout bool) {
 	var cmd *exec.Cmd
 	if commonUtils.IsWin() {
 		lang := langUtils.GetLangByFile(filePath)
@@ -103,7 +162,7 @@ func ExecScriptFile(filePath string) (string, string) {
 		if strings.ToLower(lang) != "bat" {
 			if vari.Interpreter != "" {
 				scriptInterpreter = vari.Interpreter
-				fmt.Printf("use interpreter %s for script %s\n", scriptInterpreter, filePath)
+				logUtils.Screen(fmt.Sprintf("use interpreter %s for script %s", scriptInterpreter, filePath))
 			} else {
 				scriptInterpreter = commonUtils.GetFieldVal(vari.Config, stringUtils.Ucfirst(lang))
 			}
@@ -117,8 +176,8 @@ func ExecScriptFile(filePath string) (string, string) {
 		} else if strings.ToLower(lang) == "bat" {
 			cmd = exec.Command("cmd", "/C", filePath)
 		} else {
-			fmt.Printf("use interpreter %s for script %s\n", scriptInterpreter, filePath)
-			i118Utils.I118Prt.Printf("no_interpreter_for_run", filePath, lang)
+			logUtils.Screen(fmt.Sprintf("use interpreter %s for script %s", scriptInterpreter, filePath))
+			logUtils.Screen(i118Utils.I118Prt.Sprintf("no_interpreter_for_run", filePath, lang))
 		}
 	} else {
 		err := os.Chmod(filePath, 0777)
@@ -137,7 +196,11 @@ func ExecScriptFile(filePath string) (string, string) {
 	if cmd == nil {
 		msg := "error cmd is nil"
 		logUtils.Screen(msg)
-		return "", fmt.Sprint(msg)
+		return "", fmt.Sprint(msg), false
+	}
+
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
 	}
 
 	stdout, err1 := cmd.StdoutPipe()
@@ -145,14 +208,24 @@ func ExecScriptFile(filePath string) (string, string) {
 
 	if err1 != nil {
 		fmt.Println(err1)
-		return "", fmt.Sprint(err1)
+		return "", fmt.Sprint(err1), false
 	} else if err2 != nil {
 		fmt.Println(err2)
-		return "", fmt.Sprint(err2)
+		return "", fmt.Sprint(err2), false
 	}
 
+	setProcessGroup(cmd)
 	cmd.Start()
 
+	var timeoutFlag int32
+	if timeout > 0 {
+		timer := time.AfterFunc(time.Duration(timeout)*time.Second, func() {
+			atomic.StoreInt32(&timeoutFlag, 1)
+			killProcessTree(cmd)
+		})
+		defer timer.Stop()
+	}
+
 	reader1 := bufio.NewReader(stdout)
 	output1 := make([]string, 0)
 	for {
@@ -175,5 +248,6 @@ func ExecScriptFile(filePath string) (string, string) {
 
 	cmd.Wait()
 
-	return strings.Join(output1, ""), strings.Join(output2, "")
+	isTimeout = atomic.LoadInt32(&timeoutFlag) == 1
+	return strings.Join(output1, ""), strings.Join(output2, ""), isTimeout
 }
@@ -1,7 +1,9 @@
 package stringUtils
 
 import (
+	"math"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -19,3 +21,164 @@ func MatchString(expect string, actual string, langType string) bool {
 	pass, _ := regexp.MatchString(expect, actual)
 	return pass
 }
+
+var (
+	numberRegx     = regexp.MustCompile(`[+\-]?[0-9]*\.?[0-9]+([eE][+\-]?[0-9]+)?`)
+	lineCountRegx  = regexp.MustCompile(`^lines\s*(==|!=|>=|<=|>|<)\s*([0-9]+)$`)
+	approxRegx     = regexp.MustCompile(`^(\S+)\s*(±|\+-|\+/-)\s*(\S+)$`)
+	expectOperator = []string{"==", "!=", "!~", "~i", "~=", ">=", "<=", ">", "<"}
+)
+
+const opExpectPrefix = "op:"
+
+// AssertExpect checks an actual line against an expect with an optional operator,
+// returns the result and the actual value to show in report.
+//
+//	`regx`          match regular expression or format string
+//	op:== str       equal to str
+//	op:!= str       not equal to str
+//	op:!~ str       not contain str
+//	op:~i str       contain str, case insensitive
+//	op:~= 3.14 ±0.01, op:> 100, op:>= 100, op:< 100, op:<= 100
+//	                compare the last number in actual, like 150 in "v2 took 150ms",
+//	                so that the value should be at the end of line
+//	op:lines == 3   compare the lines count of multi-line actual, also !=, >, >=, < and <=
+//	json:$.a[0].b == "ok", xpath://a/@b == 200
+//	                compare the value resolved from json or xml actual, see AssertPathExpect
+//	str             contain str
+//
+// Breaking: operators only work after the op: prefix, so that an existing expect like "== done =="
+// or "> 3 items" is still a literal one. Expects like "== ok" written for operators without
+// the prefix are checked as literal now, and should be changed to "op:== ok".
+func AssertExpect(expect string, actual string, langType string) (pass bool, shown string) {
+	expect = strings.TrimSpace(expect)
+	shown = actual
+
+	if len(expect) > 1 && expect[:1] == "`" && expect[len(expect)-1:] == "`" {
+		pass = MatchString(expect[1:len(expect)-1], actual, langType)
+		return
+	}
+
+	if IsPathExpect(expect) {
+		pass, shown = AssertPathExpect(expect, actual, langType)
+		return
+	}
+
+	if !IsOperatorExpect(expect) {
+		pass = strings.Contains(actual, expect)
+		return
+	}
+	expect = strings.TrimSpace(expect[len(opExpectPrefix):])
+
+	if arr := lineCountRegx.FindStringSubmatch(expect); arr != nil {
+		expectCount, _ := strconv.ParseFloat(arr[2], 64)
+		count := CountActualLines(actual)
+		shown = strconv.Itoa(count)
+		pass = compareNumber(float64(count), arr[1], expectCount, 0)
+		return
+	}
+
+	operator, value := parseExpectOperator(expect)
+	actualTrim := strings.TrimSpace(actual)
+
+	switch operator {
+	case "==":
+		pass = actualTrim == value
+	case "!=":
+		pass = actualTrim != value
+	case "!~":
+		pass = !strings.Contains(actual, value)
+	case "~i":
+		pass = strings.Contains(strings.ToLower(actual), strings.ToLower(value))
+	case "~=", ">=", "<=", ">", "<":
+		pass = assertNumber(operator, value, actual)
+	default:
+		shown = "invalid operator " + expect
+	}
+
+	return
+}
+
+// expect without any operator, which can be replaced by actual directly
+func IsLiteralExpect(expect string) bool {
+	expect = strings.TrimSpace(expect)
+
+	if len(expect) > 1 && expect[:1] == "`" && expect[len(expect)-1:] == "`" {
+		return false
+	}
+	return !IsPathExpect(expect) && !IsOperatorExpect(expect)
+}
+
+// expect with the op: prefix, like "op:== success"
+func IsOperatorExpect(expect string) bool {
+	return strings.HasPrefix(strings.TrimSpace(expect), opExpectPrefix)
+}
+
+// lines of multi-line actual are joined with " | " when reading log
+func CountActualLines(actual string) int {
+	if strings.TrimSpace(actual) == "" {
+		return 0
+	}
+	return len(strings.Split(actual, " | "))
+}
+
+func parseExpectOperator(expect string) (operator string, value string) {
+	for _, op := range expectOperator { // two-char operators are before single ones
+		if strings.HasPrefix(expect, op+" ") {
+			return op, strings.TrimSpace(expect[len(op):])
+		}
+	}
+
+	return "", expect
+}
+
+func assertNumber(operator string, value string, actual string) bool {
+	numbers := numberRegx.FindAllString(actual, -1)
+	if len(numbers) == 0 {
+		return false
+	}
+	actualStr := numbers[len(numbers)-1]
+	actualNum, err := strconv.ParseFloat(actualStr, 64)
+	if err != nil {
+		return false
+	}
+
+	tolerance := 0.0
+	if operator == "~=" {
+		if arr := approxRegx.FindStringSubmatch(value); arr != nil {
+			value = arr[1]
+			tolerance, err = strconv.ParseFloat(arr[3], 64)
+			if err != nil {
+				return false
+			}
+		}
+	}
+
+	expectNum, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+
+	return compareNumber(actualNum, operator, expectNum, tolerance)
+}
+
+func compareNumber(actual float64, operator string, expect float64, tolerance float64) bool {
+	switch operator {
+	case "==":
+		return actual == expect
+	case "!=":
+		return actual != expect
+	case "~=":
+		return math.Abs(actual-expect) <= math.Abs(tolerance)
+	case ">":
+		return actual > expect
+	case ">=":
+		return actual >= expect
+	case "<":
+		return actual < expect
+	case "<=":
+		return actual <= expect
+	}
+
+	return false
+}
@@ -0,0 +1,69 @@
+package stringUtils
+
+import "testing"
+
+func TestAssertExpectLiteral(t *testing.T) {
+	cases := []struct {
+		expect string
+		actual string
+		pass   bool
+	}{
+		{"== done ==", "== done ==", true},
+		{"== done ==", "done ==", false},
+		{"> 3 items", "found > 3 items", true},
+		{"> 3 items", "5", false},
+		{"!= fail", "status != fail", true},
+		{"!= fail", "ok", false},
+		{"<= 10", "limit <= 10", true},
+		{"~i SUCCESS", "~i SUCCESS", true},
+		{"~i SUCCESS", "success", false},
+		{"lines == 2", "lines == 2", true},
+		{"lines == 2", "line 1 | line 2", false},
+		{"success", "all success", true},
+		{"`^all %s$`", "all success", true},
+	}
+
+	for _, c := range cases {
+		pass, shown := AssertExpect(c.expect, c.actual, "")
+		if pass != c.pass || shown != c.actual {
+			t.Errorf("AssertExpect(%q, %q) = %v, %q, want %v", c.expect, c.actual, pass, shown, c.pass)
+		}
+		if !IsLiteralExpect(c.expect) && c.expect[:1] != "`" {
+			t.Errorf("IsLiteralExpect(%q) = false, want true", c.expect)
+		}
+	}
+}
+
+func TestAssertExpectOperator(t *testing.T) {
+	cases := []struct {
+		expect string
+		actual string
+		pass   bool
+		shown  string
+	}{
+		{"op:== success", " success ", true, " success "},
+		{"op:== success", "all success", false, "all success"},
+		{"op:!= fail", "pass", true, "pass"},
+		{"op:!~ error", "no problem", true, "no problem"},
+		{"op:!~ error", "an error", false, "an error"},
+		{"op:~i SUCCESS", "all success", true, "all success"},
+		{"op:~= 3.14 ±0.01", "pi=3.1415", true, "pi=3.1415"},
+		{"op:~= 3.14 +-0.001", "pi=3.1415", false, "pi=3.1415"},
+		{"op:< 100", "api v2 response time 86ms", true, "api v2 response time 86ms"},
+		{"op:>= 100", "api v2 response time 86ms", false, "api v2 response time 86ms"},
+		{"op:> 3", "no number", false, "no number"},
+		{"op:lines == 2", "line 1 | line 2", true, "2"},
+		{"op: lines > 2", "line 1 | line 2", false, "2"},
+		{"op:= x", "x", false, "invalid operator = x"},
+	}
+
+	for _, c := range cases {
+		pass, shown := AssertExpect(c.expect, c.actual, "")
+		if pass != c.pass || shown != c.shown {
+			t.Errorf("AssertExpect(%q, %q) = %v, %q, want %v, %q", c.expect, c.actual, pass, shown, c.pass, c.shown)
+		}
+		if IsLiteralExpect(c.expect) {
+			t.Errorf("IsLiteralExpect(%q) = true, want false", c.expect)
+		}
+	}
+}
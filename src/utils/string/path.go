@@ -0,0 +1,270 @@
+package stringUtils
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	jsonPathRegx  = regexp.MustCompile(`^(\.[^.\[]+|\[\d+\]|\['[^']*'\]|\["[^"]*"\])`)
+	xpathStepRegx = regexp.MustCompile(`^([^\[]+)(\[(\d+)\])?$`)
+)
+
+// AssertPathExpect checks expect like json:$.data.items[0].status == "ok" or xpath://result/@code == 200,
+// the value resolved by path is compared with operators in AssertExpect, or only need to exist if no operator.
+func AssertPathExpect(expect string, actual string, langType string) (pass bool, shown string) {
+	kind := expect[:strings.Index(expect, ":")]
+	expr := strings.TrimSpace(expect[len(kind)+1:])
+
+	path, cond := splitPathExpect(expr)
+
+	// lines of multi-line output were joined with " | " when reading log
+	content := strings.Replace(actual, " | ", "\n", -1)
+
+	var value string
+	var err error
+	if kind == "json" {
+		value, err = ResolveJsonPath(content, path)
+	} else {
+		value, err = ResolveXPath(content, path)
+	}
+	if err != nil { // the cause is shown instead of the whole output
+		return false, err.Error()
+	}
+
+	if cond == "" {
+		return true, value
+	}
+
+	// the json: or xpath: prefix already enables operators in condition
+	operator, expectValue := parseExpectOperator(cond)
+	if operator != "" {
+		cond = opExpectPrefix + operator + " " + unquote(expectValue)
+	} else {
+		cond = unquote(cond)
+	}
+
+	pass, _ = AssertExpect(cond, value, langType)
+	return pass, value
+}
+
+// splitPathExpect splits path and condition at the first space, spaces in brackets like $['a b'] are part of path
+func splitPathExpect(expr string) (path string, cond string) {
+	var quote rune
+	depth := 0
+	for idx, ch := range expr {
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case depth > 0 && (ch == '\'' || ch == '"'):
+			quote = ch
+		case ch == '[':
+			depth++
+		case ch == ']':
+			depth--
+		case (ch == ' ' || ch == '\t') && depth == 0:
+			return expr[:idx], strings.TrimSpace(expr[idx:])
+		}
+	}
+
+	return expr, ""
+}
+
+func IsPathExpect(expect string) bool {
+	return strings.HasPrefix(expect, "json:") || strings.HasPrefix(expect, "xpath:")
+}
+
+// ResolveJsonPath supports $, .key, [index] and ['key'] in path
+func ResolveJsonPath(content string, path string) (string, error) {
+	var node interface{}
+	if err := json.Unmarshal([]byte(content), &node); err != nil {
+		return "", err
+	}
+
+	path = strings.TrimPrefix(path, "$")
+	for path != "" {
+		token := jsonPathRegx.FindString(path)
+		if token == "" {
+			return "", errors.New("invalid json path " + path)
+		}
+		path = path[len(token):]
+
+		if token[0] == '[' && token[1] != '\'' && token[1] != '"' {
+			index, _ := strconv.Atoi(token[1 : len(token)-1])
+			arr, ok := node.([]interface{})
+			if !ok || index >= len(arr) {
+				return "", errors.New("no item " + token)
+			}
+			node = arr[index]
+			continue
+		}
+
+		key := token[1:]
+		if token[0] == '[' {
+			key = token[2 : len(token)-2]
+		}
+		obj, ok := node.(map[string]interface{})
+		if !ok {
+			return "", errors.New("no field " + key)
+		}
+		if node, ok = obj[key]; !ok {
+			return "", errors.New("no field " + key)
+		}
+	}
+
+	switch val := node.(type) {
+	case string:
+		return val, nil
+	case nil:
+		return "null", nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	default:
+		bytes, _ := json.Marshal(val)
+		return string(bytes), nil
+	}
+}
+
+type xmlNode struct {
+	Name     string
+	Attrs    map[string]string
+	Text     string
+	Children []*xmlNode
+}
+
+// ResolveXPath supports /name, //name, name[n], *, @attr and text() in path
+func ResolveXPath(content string, path string) (string, error) {
+	root, err := parseXml(content)
+	if err != nil {
+		return "", err
+	}
+
+	nodes := []*xmlNode{root}
+	for path != "" {
+		descendant := strings.HasPrefix(path, "//")
+		path = strings.TrimLeft(path, "/")
+
+		step := path
+		if idx := strings.Index(path, "/"); idx > -1 {
+			step = path[:idx]
+		}
+		path = path[len(step):]
+
+		if strings.HasPrefix(step, "@") || step == "text()" {
+			if path != "" || len(nodes) == 0 {
+				return "", errors.New("invalid xpath " + step + path)
+			}
+
+			if descendant {
+				nodes = append(nodes, descendants(nodes)...)
+			}
+			for _, node := range nodes {
+				if step == "text()" {
+					return strings.TrimSpace(node.Text), nil
+				}
+				if val, ok := node.Attrs[step[1:]]; ok {
+					return val, nil
+				}
+			}
+			return "", errors.New("no attribute " + step)
+		}
+
+		arr := xpathStepRegx.FindStringSubmatch(step)
+		if arr == nil {
+			return "", errors.New("invalid xpath step " + step)
+		}
+
+		candidates := make([]*xmlNode, 0)
+		for _, node := range nodes {
+			children := node.Children
+			if descendant {
+				children = descendants([]*xmlNode{node})
+			}
+
+			matched := make([]*xmlNode, 0)
+			for _, child := range children {
+				if arr[1] == "*" || child.Name == arr[1] {
+					matched = append(matched, child)
+				}
+			}
+
+			if arr[3] != "" {
+				index, _ := strconv.Atoi(arr[3])
+				if index < 1 || index > len(matched) {
+					continue
+				}
+				matched = matched[index-1 : index]
+			}
+			candidates = append(candidates, matched...)
+		}
+
+		if len(candidates) == 0 {
+			return "", errors.New("no element " + step)
+		}
+		nodes = candidates
+	}
+
+	return strings.TrimSpace(nodes[0].Text), nil
+}
+
+func parseXml(content string) (*xmlNode, error) {
+	root := &xmlNode{}
+	stack := []*xmlNode{root}
+
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		parent := stack[len(stack)-1]
+		switch elem := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{Name: elem.Name.Local, Attrs: map[string]string{}}
+			for _, attr := range elem.Attr {
+				node.Attrs[attr.Name.Local] = attr.Value
+			}
+			parent.Children = append(parent.Children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			parent.Text += string(elem)
+		}
+	}
+
+	if len(root.Children) == 0 {
+		return nil, errors.New("no xml element")
+	}
+	return root, nil
+}
+
+func descendants(nodes []*xmlNode) (ret []*xmlNode) {
+	for _, node := range nodes {
+		for _, child := range node.Children {
+			ret = append(ret, child)
+			ret = append(ret, descendants([]*xmlNode{child})...)
+		}
+	}
+	return
+}
+
+func unquote(str string) string {
+	if len(str) > 1 && (str[0] == '"' && str[len(str)-1] == '"' || str[0] == '\'' && str[len(str)-1] == '\'') {
+		return str[1 : len(str)-1]
+	}
+	return str
+}
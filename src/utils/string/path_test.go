@@ -0,0 +1,106 @@
+package stringUtils
+
+import "testing"
+
+const pathTestJson = `{"code": 0, "data": {"items": [{"id": 1, "status": "ok"}, {"id": 2, "status": "failed", "tags": null}],
+	"a b": "spaced", "total": 2.5, "done": true}}`
+
+const pathTestXml = `<result code="200">
+	<items>
+		<item id="1">first</item>
+		<item id="2">second <sub>inner</sub></item>
+	</items>
+	<summary><item id="3">nested</item></summary>
+</result>`
+
+func TestResolveJsonPath(t *testing.T) {
+	cases := []struct {
+		path  string
+		value string
+		err   string
+	}{
+		{"$.code", "0", ""},
+		{"$.data.items[1].status", "failed", ""},
+		{"$.data.items[0]", `{"id":1,"status":"ok"}`, ""},
+		{"$.data.items[1].tags", "null", ""},
+		{"$.data.total", "2.5", ""},
+		{"$.data.done", "true", ""},
+		{"$['data']['a b']", "spaced", ""},
+		{`$.data["a b"]`, "spaced", ""},
+		{"$.data.items[2].id", "", "no item [2]"},
+		{"$.data.missing", "", "no field missing"},
+		{"$.code.sub", "", "no field sub"},
+		{"$.data..items", "", "invalid json path ..items"},
+	}
+
+	for _, c := range cases {
+		value, err := ResolveJsonPath(pathTestJson, c.path)
+		if errStr(err) != c.err || value != c.value {
+			t.Errorf("ResolveJsonPath(%q) = %q, %q, want %q, %q", c.path, value, errStr(err), c.value, c.err)
+		}
+	}
+
+	if _, err := ResolveJsonPath("not json", "$.a"); err == nil {
+		t.Error("ResolveJsonPath of invalid json should fail")
+	}
+}
+
+func TestResolveXPath(t *testing.T) {
+	cases := []struct {
+		path  string
+		value string
+		err   string
+	}{
+		{"/result/@code", "200", ""},
+		{"/result/items/item", "first", ""},
+		{"/result/items/item[2]/@id", "2", ""},
+		{"/result/items/item[2]/sub", "inner", ""},
+		{"/result/items/item[1]/text()", "first", ""},
+		{"/result/summary/item/@id", "3", ""},
+		{"/result/*/item/@id", "1", ""},
+		{"//sub", "inner", ""},
+		{"/result/items/item[3]", "", "no element item[3]"},
+		{"/result/missing", "", "no element missing"},
+		{"/result/@missing", "", "no attribute @missing"},
+	}
+
+	for _, c := range cases {
+		value, err := ResolveXPath(pathTestXml, c.path)
+		if errStr(err) != c.err || value != c.value {
+			t.Errorf("ResolveXPath(%q) = %q, %q, want %q, %q", c.path, value, errStr(err), c.value, c.err)
+		}
+	}
+}
+
+func TestAssertPathExpect(t *testing.T) {
+	cases := []struct {
+		expect string
+		actual string
+		pass   bool
+		shown  string
+	}{
+		{`json:$.data.items[0].status == "ok"`, pathTestJson, true, "ok"},
+		{`json:$.data.items[1].status == ok`, pathTestJson, false, "failed"},
+		{`json:$['data']['a b'] == spaced`, pathTestJson, true, "spaced"},
+		{`json:$.data.total > 2`, pathTestJson, true, "2.5"},
+		{`json:$.data.items[1]`, pathTestJson, true, `{"id":2,"status":"failed","tags":null}`},
+		{`json:$.data.items[5].id == 1`, pathTestJson, false, "no item [5]"},
+		{`xpath:/result/@code == 200`, pathTestXml, true, "200"},
+		{`xpath:/result/items/item[2]/@id != 2`, pathTestXml, false, "2"},
+		{`xpath:/result/none`, pathTestXml, false, "no element none"},
+	}
+
+	for _, c := range cases {
+		pass, shown := AssertPathExpect(c.expect, c.actual, "")
+		if pass != c.pass || shown != c.shown {
+			t.Errorf("AssertPathExpect(%q) = %v, %q, want %v, %q", c.expect, pass, shown, c.pass, c.shown)
+		}
+	}
+}
+
+func errStr(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -46,7 +46,7 @@ func U2s(form string) (to string, err error) {
 	}
 	for i, bl, br, r := 0, len(bs), bytes.NewReader(bs), uint16(0); i < bl; i += 2 {
 		binary.Read(br, binary.BigEndian, &r)
-		to += string(r)
+		to += string(rune(r))
 	}
 	return
 }
@@ -20,6 +20,7 @@ var (
 	UnitTestTool     string
 	UnitTestResult   string
 	UnitTestResults  = "results"
+	UnitTestCaseMap  string
 	ProductId        string
 
 	SessionVar  string
@@ -27,6 +28,9 @@ var (
 	RequestType string
 	RequestFix  string
 
+	ZentaoVersion string // like 18.0, biz8.0 and max4.0, returned by getconfig
+	ZentaoToken   string // token of restful api
+
 	ScriptExtToNameMap map[string]string
 	CurrScriptFile     string // scripts/tc-001.py
 	CurrResultDate     string // 2019-08-15T173802
@@ -39,8 +43,19 @@ var (
 	CurrBug        model.Bug
 	CurrBugStepIds string
 
-	Verbose     bool
-	Interpreter string
+	Verbose         bool
+	Interpreter     string
+	Parallel        int
+	Timeout         int
+	Retry           int
+	Tags            string
+	Priority        string
+	Owner           string
+	Before          string
+	After           string
+	UpdateSnapshots bool
+	JUnitReport     string
+	OutputFormat    string // format of results printed to stdout, such as tap
 
 	// server
 	RunMode     string
@@ -0,0 +1,129 @@
+package zentaoUtils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var dataTableSepRegx = regexp.MustCompile(`^[\s|:\-]+$`)
+
+// data table of a data-driven case, defined in script header as
+//
+//	data=
+//	| user  | code |
+//	| admin | 200  |
+//
+// or in a csv or json file by data=users.csv, which is relative to the script.
+// files not named by data= are ignored, even if they have the same name as script.
+func readCaseDataRows(file string, info string) (rows []map[string]string) {
+	dataFile := ReadCaseInfoField(info, "data")
+	if dataFile == "" {
+		return readDataTable(info)
+	}
+
+	if !filepath.IsAbs(dataFile) {
+		dataFile = filepath.Join(filepath.Dir(file), dataFile)
+	}
+
+	content := fileUtils.ReadFileBuf(dataFile)
+	if strings.ToLower(path.Ext(dataFile)) == ".json" {
+		rows = readDataJson(content)
+	} else {
+		rows = readDataCsv(content)
+	}
+
+	return
+}
+
+func IsDataTableStart(line string) bool {
+	pass, _ := regexp.MatchString(`^\s*data\s*=\s*$`, line)
+	return pass
+}
+
+func IsDataTableLine(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "|")
+}
+
+func readDataTable(info string) (rows []map[string]string) {
+	var header []string
+	for _, line := range strings.Split(info, "\n") {
+		if !IsDataTableLine(line) || dataTableSepRegx.MatchString(line) {
+			continue
+		}
+
+		cells := strings.Split(strings.Trim(strings.TrimSpace(line), "|"), "|")
+		for idx := range cells {
+			cells[idx] = strings.TrimSpace(cells[idx])
+		}
+
+		if header == nil {
+			header = cells
+			continue
+		}
+		rows = append(rows, genDataRow(header, cells))
+	}
+
+	return
+}
+
+func readDataCsv(content []byte) (rows []map[string]string) {
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	if err != nil || len(records) < 2 {
+		return
+	}
+
+	for _, record := range records[1:] {
+		rows = append(rows, genDataRow(records[0], record))
+	}
+
+	return
+}
+
+func readDataJson(content []byte) (rows []map[string]string) {
+	var items []map[string]interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&items); err != nil {
+		return
+	}
+
+	for _, item := range items {
+		row := map[string]string{}
+		for key, val := range item {
+			if val != nil {
+				row[key] = fmt.Sprint(val)
+			} else {
+				row[key] = ""
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return
+}
+
+func genDataRow(header []string, cells []string) map[string]string {
+	row := map[string]string{}
+	for idx, name := range header {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if idx < len(cells) {
+			row[name] = strings.TrimSpace(cells[idx])
+		} else {
+			row[name] = ""
+		}
+	}
+
+	return row
+}
@@ -5,6 +5,7 @@ import (
 	constant "github.com/easysoft/zentaoatf/src/utils/const"
 	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
 	langUtils "github.com/easysoft/zentaoatf/src/utils/lang"
+	stringUtils "github.com/easysoft/zentaoatf/src/utils/string"
 	"github.com/easysoft/zentaoatf/src/utils/vari"
 	"path"
 	"path/filepath"
@@ -13,6 +14,8 @@ import (
 	"strings"
 )
 
+var zentaoVersionRegx = regexp.MustCompile(`^([a-z]*)(\d+(\.\d+)?)`)
+
 func GenApiUri(module string, methd string, param string) string {
 	var uri string
 
@@ -25,6 +28,28 @@ func GenApiUri(module string, methd string, param string) string {
 	return uri
 }
 
+// GenRestApiUri returns uri of restful api v1, like api.php/v1/testcases/1
+func GenRestApiUri(path string) string {
+	return constant.RestApiPath + strings.TrimPrefix(path, "/")
+}
+
+// UseRestApi tells whether to use restful api v1 with token, instead of the json views with session,
+// which is decided by the version of zentao detected by getconfig.
+func UseRestApi() bool {
+	arr := zentaoVersionRegx.FindStringSubmatch(strings.ToLower(vari.ZentaoVersion))
+	if arr == nil {
+		return false
+	}
+
+	minVersion, ok := constant.RestApiVersions[arr[1]]
+	if !ok {
+		return false
+	}
+
+	version, _ := strconv.ParseFloat(arr[2], 64)
+	return version >= minVersion
+}
+
 func ScriptToExpectName(file string) string {
 	fileSuffix := path.Ext(file)
 	expectName := strings.TrimSuffix(file, fileSuffix) + ".exp"
@@ -39,11 +64,14 @@ func ScriptToExpectName(file string) string {
 //}
 
 func GetCaseInfo(file string) (bool, int, int, string) {
+	return getCaseInfoFromContent(fileUtils.ReadFile(file), langUtils.GetLangByFile(file))
+}
+
+func getCaseInfoFromContent(content string, lang string) (bool, int, int, string) {
 	var caseId int
 	var productId int
 	var title string
 
-	content := fileUtils.ReadFile(file)
 	isOldFormat := strings.Index(content, "[esac]") > -1
 	pass := CheckFileContentIsScript(content)
 	if !pass {
@@ -51,7 +79,6 @@ func GetCaseInfo(file string) (bool, int, int, string) {
 	}
 
 	caseInfo := ""
-	lang := langUtils.GetLangByFile(file)
 	regStr := ""
 	if isOldFormat {
 		regStr = `(?s)\[case\](.*)\[esac\]`
@@ -192,8 +219,12 @@ func ReadExpectIndependentArr(content string) [][]string {
 	var cpArr []string
 
 	model := ""
-	for idx, line := range lines {
-		line = strings.TrimSpace(line)
+	for idx := 0; idx < len(lines); idx++ {
+		line := strings.TrimSpace(lines[idx])
+
+		if model != "multi" && IsCheckpointLabel(line) { // expects in .exp file are matched by position
+			continue
+		}
 
 		if line == ">>" { // more than one line
 			model = "multi"
@@ -208,6 +239,10 @@ func ReadExpectIndependentArr(content string) [][]string {
 				ret = append(ret, temp)
 				cpArr = make([]string, 0)
 				model = ""
+
+				if idx < len(lines)-1 && strings.TrimSpace(lines[idx+1]) == ">>" { // skip the closing >>
+					idx = idx + 1
+				}
 			}
 		} else if line == ">>" {
 			continue
@@ -270,12 +305,15 @@ func ReadLogArrObsolete(content string) (isSkip bool, ret [][]string) {
 	return
 }
 
-func ReadLogArr(content string) (isSkip bool, ret [][]string) {
+// labels[i] is the step number that ret[i] printed for, e.g. "3.2" for ">> 3.2" line, empty if not labelled
+func ReadLogArr(content string) (isSkip bool, ret [][]string, labels []string) {
 	lines := strings.Split(content, "\n")
 
 	ret = make([][]string, 0)
+	labels = make([]string, 0)
 	var cpArr []string
 
+	label := ""
 	model := ""
 	for idx := 0; idx < len(lines); idx++ {
 		line := strings.TrimSpace(lines[idx])
@@ -285,6 +323,11 @@ func ReadLogArr(content string) (isSkip bool, ret [][]string) {
 			return
 		}
 
+		if model != "multi" && IsCheckpointLabel(line) { // label for next checkpoint
+			label = GetCheckpointLabel(line)
+			continue
+		}
+
 		if line == ">>" { // more than one line
 			model = "multi"
 			cpArr = make([]string, 0)
@@ -296,9 +339,11 @@ func ReadLogArr(content string) (isSkip bool, ret [][]string) {
 				temp = append(temp, strings.Join(cpArr, " | "))
 
 				ret = append(ret, temp)
+				labels = append(labels, label)
 				cpArr = make([]string, 0)
 
 				idx = idx + 1
+				label = ""
 				model = ""
 			}
 		} else if line == ">>" {
@@ -310,13 +355,28 @@ func ReadLogArr(content string) (isSkip bool, ret [][]string) {
 
 			cpArr = append(cpArr, line)
 			ret = append(ret, cpArr)
+			labels = append(labels, label)
 			cpArr = make([]string, 0)
+			label = ""
 		}
 	}
 
 	return
 }
 
+func IsCheckpointLabel(line string) bool {
+	pass, _ := regexp.MatchString(`^>>\s*(\d+\.)*\d+\.?$`, strings.TrimSpace(line))
+	return pass
+}
+
+// ">> 3.2." to "3.2"
+func GetCheckpointLabel(line string) string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimSpace(strings.TrimPrefix(line, ">>"))
+
+	return strings.TrimRight(line, ".")
+}
+
 func CheckFileIsScript(path string) bool {
 	content := fileUtils.ReadFile(path)
 
@@ -346,11 +406,148 @@ func ReadCaseInfo(content, lang string, isOldFormat bool) (info, checkpoints str
 		info = strings.TrimSpace(arr[1])
 		checkpoints = strings.TrimSpace(arr[2])
 
+		info, checkpoints = moveInfoFieldsFromCheckpoints(info, checkpoints)
 		return
 	}
 
 	return
 }
+
+// fields like timeout may be written after pid line, they should not be treated as steps
+func moveInfoFieldsFromCheckpoints(info, checkpoints string) (string, string) {
+	regx := regexp.MustCompile(`^\s*(` + strings.Join(constant.CaseInfoExtraFields, "|") + `)\s*=`)
+
+	infoLines := []string{info}
+	stepLines := make([]string, 0)
+	inDataTable := false
+	for _, line := range strings.Split(checkpoints, "\n") {
+		if regx.MatchString(line) {
+			infoLines = append(infoLines, strings.TrimSpace(line))
+			inDataTable = IsDataTableStart(line)
+		} else if inDataTable && IsDataTableLine(line) {
+			infoLines = append(infoLines, strings.TrimSpace(line))
+		} else {
+			stepLines = append(stepLines, line)
+			inDataTable = false
+		}
+	}
+
+	return strings.Join(infoLines, "\n"), strings.TrimSpace(strings.Join(stepLines, "\n"))
+}
+
+// ReadCaseInfoExtraLines returns lines of fields besides title, cid and pid, with the rows of data table
+func ReadCaseInfoExtraLines(info string) []string {
+	regx := regexp.MustCompile(`^\s*(` + strings.Join(constant.CaseInfoExtraFields, "|") + `)\s*=`)
+
+	lines := make([]string, 0)
+	inDataTable := false
+	for _, line := range strings.Split(info, "\n") {
+		if regx.MatchString(line) {
+			lines = append(lines, strings.TrimSpace(line))
+			inDataTable = IsDataTableStart(line)
+		} else if inDataTable && IsDataTableLine(line) {
+			lines = append(lines, strings.TrimSpace(line))
+		} else {
+			inDataTable = false
+		}
+	}
+
+	return lines
+}
+
+func ReadCaseInfoField(info string, name string) string {
+	myExp := regexp.MustCompile(`(?m)^[ \t]*` + name + `[ \t]*=[ \t]*(.*?)[ \t]*$`)
+	arr := myExp.FindStringSubmatch(info)
+
+	if len(arr) > 1 {
+		return arr[1]
+	}
+
+	return ""
+}
+
+// CaseHeader has the fields in script header, which is read once before running the script
+type CaseHeader struct {
+	CaseId    int
+	ProductId int
+	Title     string
+
+	Timeout  int
+	Setup    string // commands to run before and after the script
+	Teardown string
+
+	Tags     []string
+	Priority int
+	Owner    string
+
+	DataRows []map[string]string // run once for each row if not empty
+}
+
+func ReadCaseHeader(file string) (header CaseHeader) {
+	content := fileUtils.ReadFile(file)
+	lang := langUtils.GetLangByFile(file)
+	isOldFormat := strings.Index(content, "[esac]") > -1
+
+	_, header.CaseId, header.ProductId, header.Title = getCaseInfoFromContent(content, lang)
+
+	info, _ := ReadCaseInfo(content, lang, isOldFormat)
+	header.Timeout, _ = strconv.Atoi(ReadCaseInfoField(info, "timeout"))
+	header.Setup = ReadCaseInfoField(info, "setup")
+	header.Teardown = ReadCaseInfoField(info, "teardown")
+	header.Tags, header.Priority, header.Owner = readCaseMeta(info)
+	header.DataRows = readCaseDataRows(file, info)
+
+	return
+}
+
+// tags, priority and owner defined in script header
+func GetCaseMeta(file string) (tags []string, priority int, owner string) {
+	content := fileUtils.ReadFile(file)
+	info, _ := ReadCaseInfo(content, langUtils.GetLangByFile(file), strings.Index(content, "[esac]") > -1)
+
+	return readCaseMeta(info)
+}
+
+func readCaseMeta(info string) (tags []string, priority int, owner string) {
+	tags = make([]string, 0)
+	for _, tag := range strings.Split(ReadCaseInfoField(info, "tags"), ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	priority, _ = strconv.Atoi(ReadCaseInfoField(info, "priority"))
+	owner = ReadCaseInfoField(info, "owner")
+
+	return
+}
+
+// tagExpr is like "smoke,!slow", case should have one of the tags if any given, and none of the tags begin with '!'
+func MatchCaseTags(tags []string, tagExpr string) bool {
+	hasIncludes := false
+	matchIncludes := false
+
+	for _, item := range strings.Split(tagExpr, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		if strings.Index(item, "!") == 0 {
+			if stringUtils.FindInArr(item[1:], tags) {
+				return false
+			}
+		} else {
+			hasIncludes = true
+			if stringUtils.FindInArr(item, tags) {
+				matchIncludes = true
+			}
+		}
+	}
+
+	return !hasIncludes || matchIncludes
+}
 func ReadCaseId(content string) string {
 	myExp := regexp.MustCompile(`(?s).*\ncid=((?U:.*))\n.*`)
 	arr := myExp.FindStringSubmatch(content)
@@ -364,6 +561,17 @@ func ReadCaseId(content string) string {
 }
 
 func GetDependentExpect(file string) (bool, string) {
+	expectIndependentFile := GetDependentExpectFile(file)
+
+	if expectIndependentFile != "" {
+		expectIndependentContent := fileUtils.ReadFile(expectIndependentFile)
+		return true, expectIndependentContent
+	}
+
+	return false, ""
+}
+
+func GetDependentExpectFile(file string) string {
 	dir := fileUtils.AddPathSepIfNeeded(filepath.Dir(file))
 	name := strings.Replace(filepath.Base(file), path.Ext(file), ".exp", -1)
 	expectIndependentFile := dir + name
@@ -373,9 +581,8 @@ func GetDependentExpect(file string) (bool, string) {
 	}
 
 	if fileUtils.FileExist(expectIndependentFile) {
-		expectIndependentContent := fileUtils.ReadFile(expectIndependentFile)
-		return true, expectIndependentContent
+		return expectIndependentFile
 	}
 
-	return false, ""
+	return ""
 }
@@ -5,13 +5,13 @@ import (
 	"github.com/easysoft/zentaoatf/src/action"
 	"github.com/easysoft/zentaoatf/src/server"
 	serverConst "github.com/easysoft/zentaoatf/src/server/utils/const"
+	testingService "github.com/easysoft/zentaoatf/src/service/testing"
 	commonUtils "github.com/easysoft/zentaoatf/src/utils/common"
 	configUtils "github.com/easysoft/zentaoatf/src/utils/config"
 	"github.com/easysoft/zentaoatf/src/utils/const"
 	fileUtils "github.com/easysoft/zentaoatf/src/utils/file"
 	i118Utils "github.com/easysoft/zentaoatf/src/utils/i118"
 	logUtils "github.com/easysoft/zentaoatf/src/utils/log"
-	stringUtils "github.com/easysoft/zentaoatf/src/utils/string"
 	"github.com/easysoft/zentaoatf/src/utils/vari"
 	"github.com/fatih/color"
 	"os"
@@ -25,6 +25,8 @@ var (
 	language        string
 	independentFile bool
 	keywords        string
+	format          string
+	last            int
 
 	productId string
 	moduleId  string
@@ -72,8 +74,21 @@ func main() {
 	flagSet.StringVar(&keywords, "k", "", "")
 	flagSet.StringVar(&keywords, "keywords", "", "")
 
+	flagSet.StringVar(&format, "format", "", "")
+	flagSet.IntVar(&last, "n", 10, "")
+
 	flagSet.BoolVar(&noNeedConfirm, "y", false, "")
 	flagSet.BoolVar(&vari.Verbose, "verbose", false, "")
+	flagSet.IntVar(&vari.Parallel, "parallel", 0, "")
+	flagSet.IntVar(&vari.Timeout, "timeout", 0, "")
+	flagSet.IntVar(&vari.Retry, "retry", 0, "")
+	flagSet.StringVar(&vari.Tags, "tags", "", "")
+	flagSet.StringVar(&vari.Priority, "priority", "", "")
+	flagSet.StringVar(&vari.Owner, "owner", "", "")
+	flagSet.StringVar(&vari.Before, "before", "", "")
+	flagSet.StringVar(&vari.After, "after", "", "")
+	flagSet.BoolVar(&vari.UpdateSnapshots, "update-snapshots", false, "")
+	flagSet.StringVar(&vari.JUnitReport, "junit-report", "", "")
 
 	flagSet.IntVar(&vari.Port, "P", 0, "")
 	flagSet.IntVar(&vari.Port, "port", 0, "")
@@ -85,6 +100,7 @@ func main() {
 	flagSet.StringVar(&placeholder, "v", "", "")
 
 	flagSet.StringVar(&vari.UnitTestResult, "result", "", "")
+	flagSet.StringVar(&vari.UnitTestCaseMap, "case-map", "", "")
 
 	flagSet.StringVar(&debug, "debug", "", "")
 
@@ -135,6 +151,22 @@ func main() {
 			action.CommitBug(files)
 		}
 
+	case "report":
+		files := fileUtils.GetFilesFromParams(os.Args[2:])
+		if err := flagSet.Parse(os.Args[len(files)+2:]); err == nil {
+			action.GenReport(files, format)
+		}
+
+	case "history":
+		if err := flagSet.Parse(os.Args[2:]); err == nil {
+			action.History(last)
+		}
+
+	case "diff":
+		if err := flagSet.Parse(os.Args[2:]); err == nil {
+			action.Diff(flagSet.Args())
+		}
+
 	case "list", "ls", "-l":
 		files := fileUtils.GetFilesFromParams(os.Args[2:])
 		if err := flagSet.Parse(os.Args[len(files)+2:]); err == nil {
@@ -158,7 +190,7 @@ func main() {
 		action.Clean()
 
 	case "help", "-h", "-help", "--help":
-		logUtils.PrintUsage()
+		logUtils.PrintUsage(testingService.GetUnitTestTypes())
 
 	default: // run
 		flagSet.Parse(os.Args[1:])
@@ -175,13 +207,13 @@ func main() {
 
 			run(args)
 		} else {
-			logUtils.PrintUsage()
+			logUtils.PrintUsage(testingService.GetUnitTestTypes())
 		}
 	}
 }
 
 func run(args []string) {
-	if len(args) >= 3 && stringUtils.FindInArr(args[2], constant.UnitTestTypes) { // unit test
+	if len(args) >= 3 && testingService.IsUnitTestType(args[2]) { // unit test
 		// junit -p 1 mvn clean package test
 		vari.UnitTestType = args[2]
 		end := 8
@@ -191,25 +223,20 @@ func run(args []string) {
 		flagSet.Parse(args[3:])
 
 		start := 3
-		if vari.UnitTestResult != "" {
+		if vari.UnitTestResult != "" { // keep empty if not provided, so that built-in dir of tool is used
 			start = start + 2
-		} else {
-			vari.UnitTestResult = "./"
 		}
 		if productId != "" {
 			start = start + 2
 			vari.ProductId = productId
 		}
+		if vari.UnitTestCaseMap != "" {
+			start = start + 2
+		}
 		if vari.Verbose {
 			start = start + 1
 		}
 
-		if args[start] == constant.UnitTestToolMvn {
-			vari.UnitTestTool = constant.UnitTestToolMvn
-		} else if args[start] == constant.UnitTestToolRobot {
-			vari.UnitTestTool = constant.UnitTestToolRobot
-		}
-
 		cmd := strings.Join(args[start:], " ")
 
 		action.RunUnitTest(cmd)
@@ -220,6 +247,14 @@ func run(args []string) {
 		if err == nil {
 			vari.ProductId = productId
 
+			if format == constant.OutputFormatTap { // only tap is printed to stdout
+				vari.OutputFormat = format
+				logUtils.RedirectToStderr()
+			} else if format != "" {
+				logUtils.PrintToCmd(i118Utils.I118Prt.Sprintf("report_format_not_supported", format), color.FgRed)
+				return
+			}
+
 			if len(files) == 0 {
 				files = append(files, ".")
 			}
@@ -227,9 +262,9 @@ func run(args []string) {
 			if vari.Interpreter != "" {
 				logUtils.PrintToWithColor(i118Utils.I118Prt.Sprintf("run_with_specific_interpreter", vari.Interpreter), color.FgCyan)
 			}
-			action.RunZTFTest(files, suiteId, taskId)
+			action.RunZTFTest(files, suiteId, taskId, noNeedConfirm)
 		} else {
-			logUtils.PrintUsage()
+			logUtils.PrintUsage(testingService.GetUnitTestTypes())
 		}
 	}
 }